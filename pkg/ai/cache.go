@@ -0,0 +1,119 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/log"
+	"github.com/pkg/errors"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+const (
+	summaryCacheDirName = "ai-summaries"
+	summaryCacheTTL     = 30 * 24 * time.Hour
+)
+
+// SummaryCacheKey returns a cache key identifying a summary of the base..head commit range,
+// created by the given model and prompt.
+func SummaryCacheKey(baseSHA string, headSHA string, model string, prompt Prompt) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join([]string{baseSHA + ".." + headSHA, model, prompt.Hash()}, "\n")))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LoadCachedSummary returns a previously cached summary, if one exists for the key.
+// Expired, empty and unreadable cache entries are ignored.
+func LoadCachedSummary(key string) (string, bool) {
+	filename, err := summaryCacheFilename(key)
+	if err != nil {
+		log.WithError(err).Debug("Failed to resolve AI summary cache file")
+
+		return "", false
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.WithError(err).Debug("Failed to stat cached AI summary")
+		}
+
+		return "", false
+	}
+	if time.Since(info.ModTime()) >= summaryCacheTTL {
+		log.Debug("Cached AI summary expired")
+
+		return "", false
+	}
+
+	summary, err := os.ReadFile(filename)
+	if err != nil {
+		log.WithError(err).Debug("Failed to read cached AI summary")
+
+		return "", false
+	}
+	if strings.TrimSpace(string(summary)) == "" {
+		log.Debug("Cached AI summary is empty")
+
+		return "", false
+	}
+
+	return string(summary), true
+}
+
+// SaveCachedSummary caches a summary under the key and prunes expired cache entries.
+func SaveCachedSummary(key string, summary string) error {
+	filename, err := summaryCacheFilename(key)
+	if err != nil {
+		return err
+	}
+
+	cacheDir := filepath.Dir(filename)
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "Failed to create AI summary cache dir")
+	}
+
+	if err := utils.WriteFile(filename, []byte(summary)); err != nil {
+		return errors.Wrap(err, "Failed to cache AI summary")
+	}
+
+	pruneSummaryCache(cacheDir)
+
+	return nil
+}
+
+func summaryCacheFilename(key string) (string, error) {
+	cacheDir, err := config.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, summaryCacheDirName, key+".md"), nil
+}
+
+func pruneSummaryCache(cacheDir string) {
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		log.WithError(err).Debug("Failed to list AI summary cache entries")
+
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < summaryCacheTTL {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(cacheDir, entry.Name())); err != nil {
+			log.WithError(err).Debugf("Failed to remove expired AI summary cache entry '%s'", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package ai_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/ai"
+)
+
+func Test_SummaryCacheKey(t *testing.T) {
+	a := assert.New(t)
+
+	prompt := ai.Prompt{System: "system", User: "user"}
+	key := ai.SummaryCacheKey("base", "head", "gpt-4o", prompt)
+
+	a.Len(key, 64)
+	a.Equal(key, ai.SummaryCacheKey("base", "head", "gpt-4o", prompt))
+	a.NotEqual(key, ai.SummaryCacheKey("other", "head", "gpt-4o", prompt))
+	a.NotEqual(key, ai.SummaryCacheKey("base", "other", "gpt-4o", prompt))
+	a.NotEqual(key, ai.SummaryCacheKey("base", "head", "gpt-4o-mini", prompt))
+	a.NotEqual(key, ai.SummaryCacheKey("base", "head", "gpt-4o", ai.Prompt{System: "system", User: "other"}))
+}
+
+func Test_SummaryCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, filename string)
+		expected string
+		found    bool
+	}{
+		{
+			name:     "cached",
+			setup:    func(_ *testing.T, _ string) {},
+			expected: "Adds foo",
+			found:    true,
+		},
+		{
+			name: "expired",
+			setup: func(t *testing.T, filename string) {
+				expired := time.Now().Add(-31 * 24 * time.Hour)
+				assert.NoError(t, os.Chtimes(filename, expired, expired))
+			},
+		},
+		{
+			name: "empty",
+			setup: func(t *testing.T, filename string) {
+				assert.NoError(t, os.WriteFile(filename, []byte("\n"), 0o600))
+			},
+		},
+		{
+			name: "unreadable",
+			setup: func(t *testing.T, filename string) {
+				assert.NoError(t, os.Remove(filename))
+				assert.NoError(t, os.Mkdir(filename, 0o755))
+			},
+		},
+		{
+			name: "missing",
+			setup: func(t *testing.T, filename string) {
+				assert.NoError(t, os.Remove(filename))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+
+			key := ai.SummaryCacheKey("base", "head", "gpt-4o", ai.Prompt{User: test.name})
+			a.NoError(ai.SaveCachedSummary(key, "Adds foo"))
+			test.setup(t, filepath.Join(home, ".config", "gh-prx", "cache", "ai-summaries", key+".md"))
+
+			summary, found := ai.LoadCachedSummary(key)
+			a.Equal(test.found, found)
+			a.Equal(test.expected, summary)
+		})
+	}
+}
+
+func Test_SaveCachedSummary_PrunesExpiredEntries(t *testing.T) {
+	a := assert.New(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	expiredKey := ai.SummaryCacheKey("base", "head", "gpt-4o", ai.Prompt{User: "expired"})
+	a.NoError(ai.SaveCachedSummary(expiredKey, "Adds foo"))
+	expiredFilename := filepath.Join(home, ".config", "gh-prx", "cache", "ai-summaries", expiredKey+".md")
+	expired := time.Now().Add(-31 * 24 * time.Hour)
+	a.NoError(os.Chtimes(expiredFilename, expired, expired))
+
+	a.NoError(ai.SaveCachedSummary(ai.SummaryCacheKey("base", "head", "gpt-4o", ai.Prompt{User: "new"}), "Adds bar"))
+
+	_, err := os.Stat(expiredFilename)
+	a.ErrorIs(err, os.ErrNotExist)
+}
@@ -0,0 +1,33 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/caarlos0/log"
+	"github.com/pkg/errors"
+)
+
+// ChecklistAnswer is an AI-proposed answer to a PR checklist item.
+type ChecklistAnswer struct {
+	Index  int    `json:"index"`
+	Answer string `json:"answer"`
+	Reason string `json:"reason"`
+}
+
+// AnswerChecklist proposes answers to PR checklist items based on a rendered prompt (see RenderPrompts).
+func AnswerChecklist(ctx context.Context, model string, prompt Prompt) ([]ChecklistAnswer, error) {
+	log.Debug(fmt.Sprintf("Answering the PR checklist using AI based on prompt:\n%s", prompt.User))
+
+	out, err := complete(ctx, model, prompt, 1024)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to answer PR checklist using AI")
+	}
+
+	answers := []ChecklistAnswer{}
+	if err := parseJSONArray(out, &answers); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse AI checklist answers")
+	}
+
+	return answers, nil
+}
@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+)
+
+// Prompt is a rendered pair of system and user prompts.
+type Prompt struct {
+	System string
+	User   string
+}
+
+// Hash returns a stable hash of the prompt contents.
+func (p Prompt) Hash() string {
+	h := sha256.New()
+	h.Write([]byte(p.System))
+	h.Write([]byte{0})
+	h.Write([]byte(p.User))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func IsAISummarizerAvailable() bool {
+	return config.GetOpenAIApiKey() != ""
+}
+
+// RenderPrompts renders the system and user prompt templates with the given data.
+func RenderPrompts(promptCfg config.AIPromptConfig, funcMaps template.FuncMap, data map[string]any) (Prompt, error) {
+	system, err := renderPrompt("ai-system-prompt-tpl", promptCfg.SystemPrompt, funcMaps, data)
+	if err != nil {
+		return Prompt{}, err
+	}
+
+	user, err := renderPrompt("ai-user-prompt-tpl", promptCfg.UserPrompt, funcMaps, data)
+	if err != nil {
+		return Prompt{}, err
+	}
+
+	return Prompt{System: system, User: user}, nil
+}
+
+func renderPrompt(name string, prompt string, funcMaps template.FuncMap, data map[string]any) (string, error) {
+	tpl, err := template.New(name).Funcs(funcMaps).Parse(prompt)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to parse AI prompt template '%s'", name)
+	}
+
+	res := bytes.Buffer{}
+	if err := tpl.Option("missingkey=zero").Execute(&res, data); err != nil {
+		return "", errors.Wrapf(err, "Failed to template AI prompt '%s'", name)
+	}
+
+	return res.String(), nil
+}
+
+func newChatCompletionRequest(model string, prompt Prompt, maxTokens int) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model:     model,
+		MaxTokens: maxTokens,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: prompt.System,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt.User,
+			},
+		},
+	}
+}
+
+func complete(ctx context.Context, model string, prompt Prompt, maxTokens int) (string, error) {
+	client := openai.NewClient(config.GetOpenAIApiKey())
+
+	resp, err := client.CreateChatCompletion(ctx, newChatCompletionRequest(model, prompt, maxTokens))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create chat completion")
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", errors.New("AI returned no choices")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+// completeStream streams the completion to out as it arrives and returns the full completion.
+func completeStream(ctx context.Context, model string, prompt Prompt, maxTokens int, out io.Writer) (string, error) {
+	client := openai.NewClient(config.GetOpenAIApiKey())
+
+	req := newChatCompletionRequest(model, prompt, maxTokens)
+	req.Stream = true
+	stream, err := client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create chat completion stream")
+	}
+	defer stream.Close()
+
+	sb := strings.Builder{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return "", errors.Wrap(ctxErr, "Failed to receive chat completion stream")
+			}
+
+			return "", errors.Wrap(err, "Failed to receive chat completion stream")
+		}
+
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
+		delta := resp.Choices[0].Delta.Content
+		sb.WriteString(delta)
+		if _, err := io.WriteString(out, delta); err != nil {
+			return "", errors.Wrap(err, "Failed to write chat completion stream")
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// parseJSONArray parses the first JSON array found in a completion into v.
+// Models tend to wrap JSON in markdown code fences or add some prose around it.
+func parseJSONArray(out string, v any) error {
+	start := strings.Index(out, "[")
+	end := strings.LastIndex(out, "]")
+	if start == -1 || end < start {
+		return errors.Errorf("Failed to find a JSON array in the AI response:\n%s", out)
+	}
+
+	if err := json.Unmarshal([]byte(out[start:end+1]), v); err != nil {
+		return errors.Wrapf(err, "Failed to parse the JSON array in the AI response:\n%s", out)
+	}
+
+	return nil
+}
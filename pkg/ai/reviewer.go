@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	diffFileMatcher = regexp.MustCompile(`^\+\+\+ b/(.*)$`)
+	diffHunkMatcher = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+)
+
+// ReviewFinding is a single issue found by the AI reviewer, anchored to a line in the new version of a file.
+type ReviewFinding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// ReviewGitDiffOutput reviews a pull request based on a rendered prompt (see RenderPrompts).
+func ReviewGitDiffOutput(ctx context.Context, model string, prompt Prompt) ([]ReviewFinding, error) {
+	log.Debug(fmt.Sprintf("Creating an AI-powered review based on prompt:\n%s", prompt.User))
+
+	out, err := complete(ctx, model, prompt, 2048)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to review git diff output using AI")
+	}
+
+	return ParseReviewFindings(out)
+}
+
+// ParseReviewFindings parses the JSON array of findings in the AI reviewer response.
+func ParseReviewFindings(out string) ([]ReviewFinding, error) {
+	findings := []ReviewFinding{}
+	if err := parseJSONArray(out, &findings); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse AI review findings")
+	}
+
+	return findings, nil
+}
+
+// DiffLineRanges are the line ranges of the new version of each file in a diff, by file path.
+// GitHub only accepts review comments on these lines.
+type DiffLineRanges map[string][][2]int
+
+// ParseDiffLineRanges returns the line ranges of the hunks of a unified diff.
+func ParseDiffLineRanges(diff string) DiffLineRanges {
+	ranges := DiffLineRanges{}
+	file := ""
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "+++ ") {
+			// Deleted files (+++ /dev/null) have no new version
+			file = ""
+			if matches := diffFileMatcher.FindStringSubmatch(line); len(matches) > 0 {
+				file = matches[1]
+			}
+
+			continue
+		}
+
+		matches := diffHunkMatcher.FindStringSubmatch(line)
+		if len(matches) == 0 || file == "" {
+			continue
+		}
+
+		start, _ := strconv.Atoi(matches[1])
+		count := 1
+		if matches[2] != "" {
+			count, _ = strconv.Atoi(matches[2])
+		}
+		if count > 0 {
+			ranges[file] = append(ranges[file], [2]int{start, start + count - 1})
+		}
+	}
+
+	return ranges
+}
+
+// Contains returns whether a line of a file is in the diff.
+func (r DiffLineRanges) Contains(file string, line int) bool {
+	for _, lineRange := range r[file] {
+		if line >= lineRange[0] && line <= lineRange[1] {
+			return true
+		}
+	}
+
+	return false
+}
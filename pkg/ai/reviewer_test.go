@@ -0,0 +1,122 @@
+package ai_test
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/ai"
+)
+
+func Test_ParseReviewFindings(t *testing.T) {
+	tests := []struct {
+		name        string
+		out         string
+		expected    []ai.ReviewFinding
+		expectedErr bool
+	}{
+		{
+			name: "array",
+			out:  `[{"file": "main.go", "line": 3, "severity": "warning", "message": "Unused variable"}]`,
+			expected: []ai.ReviewFinding{
+				{File: "main.go", Line: 3, Severity: "warning", Message: "Unused variable"},
+			},
+		},
+		{
+			name: "fenced array",
+			out: heredoc.Doc(`
+				Here are the findings:
+				` + "```json" + `
+				[{"file": "a.go", "line": 1, "severity": "error", "message": "Nil dereference"}]
+				` + "```"),
+			expected: []ai.ReviewFinding{{File: "a.go", Line: 1, Severity: "error", Message: "Nil dereference"}},
+		},
+		{
+			name:     "no findings",
+			out:      "[]",
+			expected: []ai.ReviewFinding{},
+		},
+		{
+			name:        "no array",
+			out:         "Looks good!",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid array",
+			out:         `[{"file": "a.go", "line": "one"}]`,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			findings, err := ai.ParseReviewFindings(test.out)
+			if test.expectedErr {
+				a.Error(err)
+
+				return
+			}
+
+			a.NoError(err)
+			a.Equal(test.expected, findings)
+		})
+	}
+}
+
+func Test_ParseDiffLineRanges(t *testing.T) {
+	a := assert.New(t)
+
+	ranges := ai.ParseDiffLineRanges(heredoc.Doc(`
+		diff --git a/main.go b/main.go
+		index 1111111..2222222 100644
+		--- a/main.go
+		+++ b/main.go
+		@@ -1,3 +1,4 @@ package main
+		 import "fmt"
+		+import "os"
+		@@ -10 +11 @@ func main() {
+		-	fmt.Println("foo")
+		+	fmt.Println("bar")
+		diff --git a/old.go b/old.go
+		deleted file mode 100644
+		--- a/old.go
+		+++ /dev/null
+		@@ -1,2 +0,0 @@
+		-package main
+		-
+		diff --git a/new.go b/new.go
+		new file mode 100644
+		--- /dev/null
+		+++ b/new.go
+		@@ -0,0 +1,2 @@
+		+package main
+		+
+	`))
+
+	a.Equal(ai.DiffLineRanges{
+		"main.go": {{1, 4}, {11, 11}},
+		"new.go":  {{1, 2}},
+	}, ranges)
+
+	tests := []struct {
+		file     string
+		line     int
+		expected bool
+	}{
+		{file: "main.go", line: 1, expected: true},
+		{file: "main.go", line: 4, expected: true},
+		{file: "main.go", line: 5, expected: false},
+		{file: "main.go", line: 11, expected: true},
+		{file: "main.go", line: 12, expected: false},
+		{file: "new.go", line: 2, expected: true},
+		{file: "old.go", line: 1, expected: false},
+		{file: "other.go", line: 1, expected: false},
+	}
+
+	for _, test := range tests {
+		a.Equal(test.expected, ranges.Contains(test.file, test.line), "%s:%d", test.file, test.line)
+	}
+}
@@ -80,6 +80,12 @@ func TemplateBranchName(cfg *config.RepositoryConfig, issue *models.Issue) (stri
 	name := normalizeBranchName(tpl.String(), cfg.Branch.TokenSeparators)
 
 	if len(name) > cfg.Branch.MaxLength {
+		if !utils.CanPrompt() {
+			log.Warnf("Branch name is longer than %d characters: %s", cfg.Branch.MaxLength, name)
+
+			return name, nil
+		}
+
 		var userReply bool
 		if err := survey.AskOne(&survey.Confirm{
 			Message: fmt.Sprintf("Branch name is somewhat long, do you want to change it?\n>> %s", name),
@@ -88,7 +94,7 @@ func TemplateBranchName(cfg *config.RepositoryConfig, issue *models.Issue) (stri
 		}
 
 		if userReply {
-			newName, err := utils.EditString(name)
+			newName, err := utils.EditString(name, "branch-name-*.txt")
 			if err != nil {
 				return "", errors.Wrap(err, "Failed to edit branch name")
 			}
@@ -103,6 +109,12 @@ func resolveIssueType(issue *models.Issue, issueTypes []string) (string, error)
 	issueType := issue.Type
 
 	if issueType == "" {
+		if !utils.CanPrompt() {
+			return "", errors.Wrapf(utils.ErrCannotPrompt,
+				"Could not determine issue type from labels, pass it with --type (one of %s)", strings.Join(issueTypes, ", "),
+			)
+		}
+
 		log.Info("Could not determine issue type from labels, asking user")
 
 		if err := survey.AskOne(&survey.Select{
@@ -116,6 +128,29 @@ func resolveIssueType(issue *models.Issue, issueTypes []string) (string, error)
 	return issueType, nil
 }
 
+// TemplateBackportBranchName renders the name of the branch backporting a pull request, from the original branch
+// fields and the backport fields (e.g. {{.Target}}).
+func TemplateBackportBranchName(cfg *config.RepositoryConfig, fields map[string]any) (string, error) {
+	log.Debug("Templating backport branch name")
+
+	funcMaps, err := utils.GenerateTemplateFunctions(cfg.Branch.TokenSeparators)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate template functions")
+	}
+
+	tpl := bytes.Buffer{}
+	t, err := template.New("backport-branch-name-tpl").Funcs(funcMaps).Parse(cfg.Backport.BranchTemplate)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to parse backport branch name template")
+	}
+
+	if err := t.Execute(&tpl, fields); err != nil {
+		return "", errors.Wrap(err, "Failed to template backport branch name")
+	}
+
+	return normalizeBranchName(tpl.String(), cfg.Branch.TokenSeparators), nil
+}
+
 func normalizeBranchName(name string, tokenSeparators []string) string {
 	runeTokenSeparators := []rune{}
 	for _, tokenSep := range tokenSeparators {
@@ -73,3 +73,22 @@ func Test_ParseBranch(t *testing.T) {
 		})
 	}
 }
+
+func Test_TemplateBackportBranchName(t *testing.T) {
+	a := assert.New(t)
+
+	cfg := &config.RepositoryConfig{}
+	cfg.SetDefaults()
+	fields := map[string]any{
+		"Type": "fix", "Issue": "", "Description": "fix-thing", "Original": "fix/fix-thing", "Target": "release/1.2",
+	}
+
+	name, err := branch.TemplateBackportBranchName(cfg, fields)
+	a.NoError(err)
+	a.Equal("backport/release/1.2/fix/fix-thing", name)
+
+	cfg.Backport.BranchTemplate = "{{.Type}}/{{with .Issue}}{{.}}-{{end}}{{.Description}}-{{.Target}}"
+	name, err = branch.TemplateBackportBranchName(cfg, fields)
+	a.NoError(err)
+	a.Equal("fix/fix-thing-release/1.2", name)
+}
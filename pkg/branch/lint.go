@@ -0,0 +1,153 @@
+package branch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+)
+
+var variableMatcher = regexp.MustCompile(`\{\{\.(\w+)\}\}`)
+
+// LintError explains why a branch name doesn't follow the branch pattern.
+type LintError struct {
+	Name    string
+	Pattern string
+	// Position is the position in the name where matching the pattern failed.
+	Position int
+	// Expected is the part of the pattern that failed to match, e.g. "{{.Type}}" or "\/".
+	Expected string
+	// Variables are the patterns of the variables in Expected, by variable name.
+	Variables map[string]string
+	// Matched are the values of the variables that matched before the failure, in order.
+	Matched []lo.Entry[string, string]
+}
+
+func (e *LintError) Error() string {
+	lines := []string{fmt.Sprintf("Branch name '%s' doesn't match the pattern '%s':", e.Name, e.Pattern)}
+	for _, m := range e.Matched {
+		lines = append(lines, fmt.Sprintf("  ✓ {{.%s}} matched '%s'", m.Key, m.Value))
+	}
+
+	expected := "the end of the branch name"
+	if e.Expected != "" {
+		expected = fmt.Sprintf("'%s'", e.Expected)
+	}
+	lines = append(lines,
+		"    "+e.Name,
+		fmt.Sprintf("    %s^ expected %s", strings.Repeat(" ", e.Position), expected),
+	)
+	for _, v := range variableMatcher.FindAllStringSubmatch(e.Expected, -1) {
+		lines = append(lines, fmt.Sprintf("  ✗ {{.%s}} should match '%s'", v[1], e.Variables[v[1]]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Lint checks that a branch name follows the branch pattern, the same way ParseBranch parses it.
+// Returns a *LintError explaining the first part of the pattern that doesn't match.
+func Lint(name string, cfg config.BranchConfig) error {
+	if _, err := ParseBranch(name, cfg); err == nil {
+		return nil
+	}
+
+	lintErr := &LintError{Name: name, Pattern: cfg.Pattern, Variables: cfg.VariablePatterns}
+	tokens := splitPattern(cfg.Pattern)
+	for i, token := range tokens {
+		prefix, err := regexp.Compile("^" + expandPattern(strings.Join(tokens[:i+1], ""), cfg.VariablePatterns))
+		if err != nil {
+			return errors.Wrap(err, "Failed to compile branch pattern")
+		}
+
+		matches := prefix.FindStringSubmatchIndex(name)
+		if matches == nil {
+			lintErr.Expected = token
+
+			return lintErr
+		}
+
+		lintErr.Position = matches[1]
+		lintErr.Matched = nil
+		for j, group := range prefix.SubexpNames() {
+			if group != "" && matches[2*j] >= 0 {
+				lintErr.Matched = append(lintErr.Matched, lo.Entry[string, string]{
+					Key: group, Value: name[matches[2*j]:matches[2*j+1]],
+				})
+			}
+		}
+	}
+
+	return lintErr
+}
+
+func expandPattern(pattern string, variablePatterns map[string]string) string {
+	return variableMatcher.ReplaceAllStringFunc(pattern, func(v string) string {
+		name := variableMatcher.FindStringSubmatch(v)[1]
+
+		return fmt.Sprintf("(?P<%s>%s)", name, variablePatterns[name])
+	})
+}
+
+// splitPattern splits a branch pattern into its top-level parts (variables, groups, character classes, escaped
+// and plain characters, along with their quantifiers), so that any prefix of the parts is a valid pattern.
+func splitPattern(pattern string) []string {
+	tokens := []string{}
+	for i := 0; i < len(pattern); {
+		end := i + 1
+		switch {
+		case strings.HasPrefix(pattern[i:], "{{"):
+			if j := strings.Index(pattern[i:], "}}"); j >= 0 {
+				end = i + j + 2
+			}
+		case pattern[i] == '\\':
+			end = min(i+2, len(pattern))
+		case pattern[i] == '(' || pattern[i] == '[':
+			end = closingIndex(pattern, i) + 1
+		}
+
+		// Quantifiers belong to the preceding part
+		for end < len(pattern) && strings.ContainsRune("?*+", rune(pattern[end])) {
+			end++
+		}
+		if end < len(pattern) && pattern[end] == '{' && !strings.HasPrefix(pattern[end:], "{{") {
+			if j := strings.Index(pattern[end:], "}"); j >= 0 {
+				end += j + 1
+			}
+		}
+
+		tokens = append(tokens, pattern[i:end])
+		i = end
+	}
+
+	return tokens
+}
+
+// closingIndex returns the index of the parenthesis or bracket closing the one at start, or the last index if
+// it's not closed.
+func closingIndex(pattern string, start int) int {
+	open, closing := pattern[start], byte(')')
+	if open == '[' {
+		closing = ']'
+	}
+
+	depth := 0
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case open:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(pattern) - 1
+}
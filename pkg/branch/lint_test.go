@@ -0,0 +1,87 @@
+package branch_test
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/branch"
+	"github.com/ilaif/gh-prx/pkg/config"
+)
+
+func Test_Lint(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		expected *branch.LintError
+	}{
+		{name: "fix/1234-fix-thing"},
+		{name: "feat/add-foo"},
+		{
+			name: "fix-thing",
+			expected: &branch.LintError{
+				Position: 3,
+				Expected: `\/`,
+				Matched:  []lo.Entry[string, string]{{Key: "Type", Value: "fix"}},
+			},
+		},
+		{
+			name:     "bug/fix-thing",
+			expected: &branch.LintError{Position: 0, Expected: "{{.Type}}"},
+		},
+		{
+			name:    "chore-update-deps",
+			pattern: "{{.Type}}-{{.Issue}}-{{.Description}}",
+			expected: &branch.LintError{
+				Position: 6,
+				Expected: "{{.Issue}}",
+				Matched:  []lo.Entry[string, string]{{Key: "Type", Value: "chore"}},
+			},
+		},
+		{
+			name:    "fix/ENG-12",
+			pattern: `{{.Type}}\/({{.Issue}}-)?[a-z]+`,
+			expected: &branch.LintError{
+				Position: 4,
+				Expected: "[a-z]+",
+				Matched:  []lo.Entry[string, string]{{Key: "Type", Value: "fix"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			cfg := config.BranchConfig{Pattern: test.pattern}
+			cfg.SetDefaults()
+
+			err := branch.Lint(test.name, cfg)
+			if test.expected == nil {
+				a.NoError(err)
+
+				return
+			}
+
+			lintErr := &branch.LintError{}
+			a.ErrorAs(err, &lintErr)
+			a.Equal(test.expected.Position, lintErr.Position)
+			a.Equal(test.expected.Expected, lintErr.Expected)
+			a.Equal(test.expected.Matched, lintErr.Matched)
+		})
+	}
+}
+
+func Test_LintError(t *testing.T) {
+	a := assert.New(t)
+
+	cfg := config.BranchConfig{}
+	cfg.SetDefaults()
+
+	err := branch.Lint("fix-thing", cfg)
+	a.EqualError(err, "Branch name 'fix-thing' doesn't match the pattern '{{.Type}}\\/({{.Issue}}-)?{{.Description}}':\n"+
+		"  ✓ {{.Type}} matched 'fix'\n"+
+		"    fix-thing\n"+
+		"       ^ expected '\\/'")
+}
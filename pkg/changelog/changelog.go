@@ -0,0 +1,129 @@
+package changelog
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/commit"
+	"github.com/ilaif/gh-prx/pkg/config"
+)
+
+// OtherSectionTitle is the title of the section of the pull requests that don't match any configured section.
+const OtherSectionTitle = "Other Changes"
+
+// Entry is a merged pull request in a changelog.
+type Entry struct {
+	Number      int
+	Title       string
+	URL         string
+	Author      string
+	Labels      []string
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+}
+
+// Section is a group of changelog entries.
+type Section struct {
+	Title   string
+	Entries []Entry
+}
+
+// Data is the data available to the changelog template.
+type Data struct {
+	Version  string
+	Date     string
+	From     string
+	To       string
+	Sections []Section
+	Entries  []Entry
+}
+
+// NewEntry creates the entry of a merged pull request. The type is taken from the branch name when it could be
+// parsed (branchType), or from the Conventional Commits prefix of the title (e.g. "feat(api): Add foo").
+func NewEntry(number int, title string, url string, author string, labels []string, branchType string) Entry {
+	entry := Entry{
+		Number:      number,
+		Title:       title,
+		URL:         url,
+		Author:      author,
+		Labels:      labels,
+		Type:        strings.ToLower(branchType),
+		Description: strings.TrimSpace(title),
+	}
+
+	if conventional, ok := commit.ParseConventional(title, ""); ok {
+		entry.Scope = conventional.Scope
+		entry.Description = conventional.Description
+		entry.Breaking = conventional.Breaking
+		if entry.Type == "" {
+			entry.Type = conventional.Type
+		}
+	}
+
+	return entry
+}
+
+// Group groups entries into the configured sections, in order. An entry is added to the first section matching
+// its type or labels, or to a trailing OtherSectionTitle section. Entries with any of the excluded labels are left
+// out, and so are empty sections.
+func Group(entries []Entry, sectionCfgs []config.ChangelogSectionConfig, excludeLabels []string) []Section {
+	sections := lo.Map(sectionCfgs, func(c config.ChangelogSectionConfig, _ int) Section {
+		return Section{Title: c.Title}
+	})
+	other := Section{Title: OtherSectionTitle}
+
+	for _, entry := range entries {
+		if lo.Some(entry.Labels, excludeLabels) {
+			continue
+		}
+
+		_, i, ok := lo.FindIndexOf(sectionCfgs, func(c config.ChangelogSectionConfig) bool {
+			return lo.Contains(c.Types, entry.Type) || lo.Some(entry.Labels, c.Labels)
+		})
+		if !ok {
+			other.Entries = append(other.Entries, entry)
+
+			continue
+		}
+		sections[i].Entries = append(sections[i].Entries, entry)
+	}
+
+	return lo.Filter(append(sections, other), func(s Section, _ int) bool { return len(s.Entries) > 0 })
+}
+
+// Render renders the changelog template.
+func Render(text string, funcMaps template.FuncMap, data Data) (string, error) {
+	tpl, err := template.New("changelog-tpl").Funcs(funcMaps).Parse(text)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to parse changelog template")
+	}
+
+	var res bytes.Buffer
+	if err := tpl.Execute(&res, data); err != nil {
+		return "", errors.Wrap(err, "Failed to template changelog")
+	}
+
+	return strings.TrimSpace(res.String()) + "\n", nil
+}
+
+// Prepend adds a changelog at the top of an existing changelog file content, below its title (if it has one).
+func Prepend(existing string, changelog string) string {
+	if existing == "" {
+		return changelog
+	}
+
+	title := ""
+	if strings.HasPrefix(existing, "# ") {
+		title, existing, _ = strings.Cut(existing, "\n")
+		title += "\n\n"
+		existing = strings.TrimLeft(existing, "\n")
+	}
+
+	return title + changelog + "\n" + existing
+}
@@ -0,0 +1,92 @@
+package changelog_test
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/changelog"
+	"github.com/ilaif/gh-prx/pkg/config"
+)
+
+func Test_NewEntry(t *testing.T) {
+	tests := []struct {
+		name       string
+		title      string
+		branchType string
+		expected   changelog.Entry
+	}{
+		{
+			name:       "type from branch",
+			title:      "fix(PROJ-1): Handle nil",
+			branchType: "Bug",
+			expected:   changelog.Entry{Type: "bug", Scope: "PROJ-1", Description: "Handle nil"},
+		},
+		{
+			name:     "type from title",
+			title:    "feat!: Drop node 16",
+			expected: changelog.Entry{Type: "feat", Description: "Drop node 16", Breaking: true},
+		},
+		{
+			name:     "no type",
+			title:    "Update README",
+			expected: changelog.Entry{Description: "Update README"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry := changelog.NewEntry(1, test.title, "", "", nil, test.branchType)
+			test.expected.Number = 1
+			test.expected.Title = test.title
+			assert.Equal(t, test.expected, entry)
+		})
+	}
+}
+
+func Test_Group(t *testing.T) {
+	a := assert.New(t)
+
+	sections := changelog.Group([]changelog.Entry{
+		{Number: 1, Type: "fix"},
+		{Number: 2, Type: "chore"},
+		{Number: 3, Type: "feat"},
+		{Number: 4, Labels: []string{"bug"}},
+		{Number: 5, Type: "fix", Labels: []string{"skip-changelog"}},
+	}, config.DefaultChangelogSections, config.DefaultChangelogExcludeLabels)
+
+	a.Equal([]string{"Features", "Bug Fixes", changelog.OtherSectionTitle},
+		lo.Map(sections, func(s changelog.Section, _ int) string { return s.Title }))
+	a.Equal([]int{1, 4}, lo.Map(sections[1].Entries, func(e changelog.Entry, _ int) int { return e.Number }))
+	a.Equal(2, sections[2].Entries[0].Number)
+}
+
+func Test_Prepend(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		expected string
+	}{
+		{
+			name:     "empty",
+			expected: "## 1.1.0\n",
+		},
+		{
+			name:     "with title",
+			existing: "# Changelog\n\n## 1.0.0\n",
+			expected: "# Changelog\n\n## 1.1.0\n\n## 1.0.0\n",
+		},
+		{
+			name:     "without title",
+			existing: "## 1.0.0\n",
+			expected: "## 1.1.0\n\n## 1.0.0\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, changelog.Prepend(test.existing, "## 1.1.0\n"))
+		})
+	}
+}
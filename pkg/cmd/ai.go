@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/pflag"
+
+	"github.com/ilaif/gh-prx/pkg/ai"
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/providers"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+// AIOpts are the AI-related options shared by the commands that template pull requests.
+type AIOpts struct {
+	NoAISummary       bool
+	RegenerateSummary bool
+	AIChecklist       bool
+	AITimeout         time.Duration
+}
+
+func addAIFlags(fl *pflag.FlagSet, opts *AIOpts) {
+	fl.BoolVar(&opts.NoAISummary, "no-ai-summary", false, "Disable AI-powered summary")
+	fl.BoolVar(
+		&opts.RegenerateSummary,
+		"regenerate-summary",
+		false,
+		"Create a new AI-powered summary instead of reusing a cached one",
+	)
+	fl.BoolVar(&opts.AIChecklist, "ai-checklist", false, "Propose answers to the PR checklist using AI")
+	fl.DurationVar(&opts.AITimeout, "ai-timeout", 0, "The maximum time to wait for an AI request (default: ai.timeout)")
+}
+
+// newAISummarizer returns a pr.AISummarizer that creates an AI-powered summary of the branch changes,
+// or an empty summary when the AI-powered summary is disabled or times out.
+func newAISummarizer(ctx context.Context,
+	setupCfg *config.SetupConfig,
+	cfg *config.RepositoryConfig,
+	b models.Branch,
+	baseBranch string,
+	commits []models.Commit,
+	aiOpts AIOpts,
+	confirm bool,
+) pr.AISummarizer {
+	return func() (string, error) {
+		if aiOpts.NoAISummary || !ai.IsAISummarizerAvailable() {
+			log.Debug("AI-powered summary is disabled")
+
+			return "", nil
+		}
+
+		aiSummary, err := createAISummary(ctx, setupCfg, cfg, b, baseBranch, commits, aiOpts, confirm)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				log.Warnf("AI-powered summary timed out after %s, skipping (see --ai-timeout)", cfg.AI.Timeout)
+
+				return "", nil
+			}
+
+			return "", err
+		}
+
+		return aiSummary, nil
+	}
+}
+
+// newAIChecklistAnswerer returns a pr.ChecklistAnswerer that proposes answers using AI,
+// or nil when AI-powered checklist answers are disabled.
+func newAIChecklistAnswerer(ctx context.Context,
+	cfg *config.RepositoryConfig,
+	b models.Branch,
+	baseBranch string,
+	commits []models.Commit,
+	aiOpts AIOpts,
+) pr.ChecklistAnswerer {
+	if !(aiOpts.AIChecklist || *cfg.AI.AnswerChecklist) || !ai.IsAISummarizerAvailable() {
+		return nil
+	}
+
+	return func(questions []string) (map[string]pr.ChecklistAnswer, error) {
+		return createAIChecklistAnswers(ctx, cfg, b, baseBranch, commits, questions), nil
+	}
+}
+
+func createAISummary(ctx context.Context,
+	setupCfg *config.SetupConfig,
+	cfg *config.RepositoryConfig,
+	b models.Branch,
+	baseBranch string,
+	commits []models.Commit,
+	aiOpts AIOpts,
+	confirm bool,
+) (string, error) {
+	gitDiffOutput, err := fetchGitDiff(baseBranch, 10)
+	if err != nil {
+		return "", err
+	}
+
+	funcMaps, err := utils.GenerateTemplateFunctions(cfg.Branch.TokenSeparators)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate template functions")
+	}
+
+	promptData := lo.Assign(b.Fields, map[string]any{
+		"Diff":           gitDiffOutput,
+		"Commits":        models.CommitSubjects(commits),
+		"CommitMessages": models.CommitMessages(commits),
+		"PRTemplate":     cfg.PR.Body,
+	})
+
+	summaryPrompts := cfg.AI.Summary.SystemPrompt + cfg.AI.Summary.UserPrompt
+	issueKey, _ := b.Fields["Issue"].(string)
+	if issueKey != "" && strings.Contains(summaryPrompts, ".IssueTitle") {
+		promptData["IssueTitle"] = fetchIssueTitle(ctx, setupCfg, cfg, issueKey)
+	}
+
+	prompt, err := ai.RenderPrompts(cfg.AI.Summary, funcMaps, promptData)
+	if err != nil {
+		return "", err
+	}
+
+	cacheKey, err := summaryCacheKey(baseBranch, b.Original, cfg.AI.Model, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	regenerate := aiOpts.RegenerateSummary
+	for {
+		aiSummary, err := generateAISummary(ctx, cfg, funcMaps, promptData, prompt, cacheKey, regenerate)
+		if err != nil {
+			return "", err
+		}
+
+		if confirm {
+			return aiSummary, nil
+		}
+
+		action := ""
+		if err := survey.AskOne(&survey.Select{
+			Message: "What would you like to do with the AI-powered summary?",
+			Options: []string{"accept", "regenerate", "edit"},
+		}, &action, survey.WithValidator(survey.Required)); err != nil {
+			return "", errors.Wrap(err, "Failed to ask for AI-powered summary action")
+		}
+
+		switch action {
+		case "accept":
+			return aiSummary, nil
+		case "regenerate":
+			regenerate = true
+		case "edit":
+			aiSummary, err = utils.EditString(aiSummary, "ai-summary-*.md")
+			if err != nil {
+				return "", errors.Wrap(err, "Failed to edit AI-powered summary")
+			}
+
+			if err := ai.SaveCachedSummary(cacheKey, aiSummary); err != nil {
+				log.WithError(err).Warn("Failed to cache AI-powered summary")
+			}
+
+			return aiSummary, nil
+		}
+	}
+}
+
+// generateAISummary streams a new AI-powered summary to the terminal, or prints the cached one.
+// Each generation is bounded by the configured AI timeout.
+func generateAISummary(ctx context.Context,
+	cfg *config.RepositoryConfig,
+	funcMaps template.FuncMap,
+	promptData map[string]any,
+	prompt ai.Prompt,
+	cacheKey string,
+	regenerate bool,
+) (string, error) {
+	if !regenerate {
+		if aiSummary, ok := ai.LoadCachedSummary(cacheKey); ok {
+			log.Info("Using cached AI-powered summary (use --regenerate-summary to create a new one)")
+			fmt.Fprintf(os.Stderr, "\n%s\n\n", strings.TrimSpace(aiSummary))
+
+			return aiSummary, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.AI.Timeout)
+	defer cancel()
+
+	log.Info("Creating an AI-powered summary:")
+	fmt.Fprintln(os.Stderr)
+	aiSummary, err := ai.SummarizeGitDiffOutput(ctx, cfg.AI.Model, prompt, os.Stderr)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		log.WithError(err).Debug("Failed to summarize git diff output")
+		// The summary may have been partially streamed, so the fallback summary is printed after it
+		fmt.Fprint(os.Stderr, "\n\n")
+		log.Warn("Failed to summarize the diff, creating an AI-powered summary of the commit messages instead:")
+		fmt.Fprintln(os.Stderr)
+		commitMessages, _ := promptData["CommitMessages"].([]string)
+		promptData = lo.Assign(promptData, map[string]any{"Diff": strings.Join(commitMessages, "\n\n")})
+		aiSummary, err = summarizeWithPromptData(ctx, cfg, funcMaps, promptData)
+		fmt.Fprint(os.Stderr, "\n\n")
+		if err != nil {
+			return "", err
+		}
+
+		// The cache key is derived from the diff prompt, so the fallback summary isn't cached
+		return aiSummary, nil
+	}
+	fmt.Fprint(os.Stderr, "\n\n")
+	if err != nil {
+		return "", err
+	}
+
+	if err := ai.SaveCachedSummary(cacheKey, aiSummary); err != nil {
+		log.WithError(err).Warn("Failed to cache AI-powered summary")
+	}
+
+	return aiSummary, nil
+}
+
+// fetchGitDiff returns a whitespace-insensitive diff of the files that changed more than
+// minChangedLines lines compared to the base branch.
+func fetchGitDiff(baseBranch string, minChangedLines int) (string, error) {
+	gitDiffCmd := heredoc.Docf(`
+			git diff %[1]s --stat=10000 |
+			grep '|' |
+			awk '{ if ($3 > %[2]d) print $1 }' |
+			xargs git diff ^%[1]s --ignore-all-space --ignore-blank-lines --ignore-space-change --unified=0 --word-diff --
+		`, baseBranch, minChangedLines)
+	gitDiffOutput, err := utils.Exec("sh", "-c", gitDiffCmd)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to fetch branch commits")
+	}
+
+	return gitDiffOutput, nil
+}
+
+func summarizeWithPromptData(
+	ctx context.Context,
+	cfg *config.RepositoryConfig,
+	funcMaps template.FuncMap,
+	promptData map[string]any,
+) (string, error) {
+	prompt, err := ai.RenderPrompts(cfg.AI.Summary, funcMaps, promptData)
+	if err != nil {
+		return "", err
+	}
+
+	return ai.SummarizeGitDiffOutput(ctx, cfg.AI.Model, prompt, os.Stderr)
+}
+
+// summaryCacheKey resolves the base and head commits and returns the AI summary cache key for them.
+func summaryCacheKey(baseBranch string, headBranch string, model string, prompt ai.Prompt) (string, error) {
+	out, err := utils.Exec("git", "rev-parse", baseBranch, headBranch)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to resolve base and head commits")
+	}
+
+	shas := strings.Fields(out)
+	if len(shas) != 2 {
+		return "", errors.Errorf("Failed to resolve base and head commits: unexpected output '%s'", out)
+	}
+
+	return ai.SummaryCacheKey(shas[0], shas[1], model, prompt), nil
+}
+
+// createAIChecklistAnswers proposes answers to the PR checklist questions using AI.
+// Failures are logged and result in no proposed answers, falling back to answering the checklist manually.
+func createAIChecklistAnswers(ctx context.Context,
+	cfg *config.RepositoryConfig,
+	b models.Branch,
+	baseBranch string,
+	commits []models.Commit,
+	questions []string,
+) map[string]pr.ChecklistAnswer {
+	ctx, cancel := context.WithTimeout(ctx, cfg.AI.Timeout)
+	defer cancel()
+
+	gitDiffOutput, err := fetchGitDiff(baseBranch, 10)
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch git diff, skipping AI-powered checklist answers")
+
+		return nil
+	}
+
+	funcMaps, err := utils.GenerateTemplateFunctions(cfg.Branch.TokenSeparators)
+	if err != nil {
+		log.WithError(err).Warn("Failed to generate template functions, skipping AI-powered checklist answers")
+
+		return nil
+	}
+
+	prompt, err := ai.RenderPrompts(cfg.AI.Checklist, funcMaps, lo.Assign(b.Fields, map[string]any{
+		"Diff":           gitDiffOutput,
+		"Commits":        models.CommitSubjects(commits),
+		"CommitMessages": models.CommitMessages(commits),
+		"Questions":      lo.Map(questions, func(q string, _ int) string { return strings.TrimSpace(q) }),
+	}))
+	if err != nil {
+		log.WithError(err).Warn("Failed to render AI checklist prompt, skipping AI-powered checklist answers")
+
+		return nil
+	}
+
+	s := utils.StartSpinner("Answering PR checklist using AI...", "Answered PR checklist")
+	aiAnswers, err := ai.AnswerChecklist(ctx, cfg.AI.Model, prompt)
+	s.Stop()
+	if err != nil {
+		log.WithError(err).Warn("Failed to answer PR checklist using AI, skipping")
+
+		return nil
+	}
+
+	answers := map[string]pr.ChecklistAnswer{}
+	for _, a := range aiAnswers {
+		answer := strings.ToLower(strings.TrimSpace(a.Answer))
+		if a.Index < 0 || a.Index >= len(questions) || !lo.Contains([]string{"yes", "no", "skip"}, answer) {
+			log.Debugf("Ignoring invalid AI checklist answer: %+v", a)
+
+			continue
+		}
+
+		answers[questions[a.Index]] = pr.ChecklistAnswer{Answer: answer, Reason: a.Reason}
+	}
+
+	return answers
+}
+
+// fetchIssueTitle fetches the title of an issue from the configured provider.
+// Failures are logged and result in an empty title, as the issue title is only a nice-to-have for the prompt.
+func fetchIssueTitle(
+	ctx context.Context,
+	setupCfg *config.SetupConfig,
+	cfg *config.RepositoryConfig,
+	issueKey string,
+) string {
+	provider, err := providers.NewIssueProvider(cfg, setupCfg)
+	if err != nil {
+		log.WithError(err).Warn("Failed to create issue provider, skipping issue title")
+
+		return ""
+	}
+
+	issue, err := provider.Get(ctx, issueKey)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to fetch issue '%s', skipping issue title", issueKey)
+
+		return ""
+	}
+
+	return issue.Title
+}
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/cli/go-gh"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/ilaif/gh-prx/pkg/branch"
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+type BackportOpts struct {
+	Target   string
+	Confirm  bool
+	Continue bool
+
+	DryRun bool
+}
+
+// backportedPR is a merged pull request to backport.
+type backportedPR struct {
+	ExistingPR
+	Commits []prCommit `json:"commits"`
+}
+
+type prCommit struct {
+	OID string `json:"oid"`
+}
+
+func NewBackportCmd() *cobra.Command {
+	opts := &BackportOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "backport {<number> | <url> | <branch>} --to <branch>",
+		Short: "Backport a merged pull request onto another branch (e.g. a release branch).",
+		Long: heredoc.Docf(`
+			Backport a merged pull request onto another branch (e.g. a release branch).
+
+			The pull request commits are cherry-picked (with %[1]s-x%[1]s) onto a new branch based on the target branch,
+			named according to the %[1]sbackport.branch_template%[1]s template. The branch is then pushed, and a pull request
+			is opened against the target branch, with a title and description rendered from the %[1]sbackport.title%[1]s
+			and %[1]sbackport.body%[1]s templates, based on the original pull request.
+
+			If cherry-picking stops due to conflicts, resolve them, run %[1]sgit cherry-pick --continue%[1]s
+			and run the command again with %[1]s--continue%[1]s.
+		`, "`"),
+		Example: heredoc.Doc(`
+			$ gh prx backport 123 --to release/1.2
+			$ gh prx backport 123 --to release/1.2 --continue # After resolving cherry-pick conflicts
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return backport(args[0], opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.StringVar(&opts.Target, "to", "", "The `branch` to backport the pull request onto")
+	_ = cmd.MarkFlagRequired("to")
+	fl.BoolVarP(&opts.Confirm, "confirm", "y", false, "Don't ask for user input")
+	fl.BoolVar(
+		&opts.Continue,
+		"continue",
+		false,
+		"Push the backport branch and open its pull request after resolving conflicts",
+	)
+	fl.BoolVar(&opts.DryRun, "dry-run", false, "Print the backport branch name without backporting")
+
+	return cmd
+}
+
+func backport(selector string, opts *BackportOpts) error { // nolint:cyclop
+	if !opts.Confirm && !opts.DryRun && !utils.CanPrompt() {
+		return errors.Wrap(utils.ErrCannotPrompt, "Pass --confirm (-y) to backport the pull request without confirmation")
+	}
+
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	originalPR, err := fetchBackportedPR(selector)
+	if err != nil {
+		return err
+	}
+	if originalPR.State != "MERGED" {
+		return errors.Errorf("Pull request #%d is %s, only merged pull requests can be backported",
+			originalPR.Number, strings.ToLower(originalPR.State))
+	}
+
+	fields := map[string]any{}
+	if b, err := branch.ParseBranch(originalPR.HeadRefName, cfg.Branch); err != nil {
+		log.WithError(err).Debug("Failed to parse branch name, branch fields are unavailable in backport templates")
+	} else {
+		fields = b.Fields
+	}
+	fields = lo.Assign(fields, map[string]any{
+		"Original":      originalPR.HeadRefName,
+		"Number":        originalPR.Number,
+		"OriginalTitle": originalPR.Title,
+		"OriginalBody":  pr.UnmarkGeneratedSection(originalPR.Body),
+		"OriginalURL":   originalPR.URL,
+		"Target":        opts.Target,
+	})
+
+	backportBranch, err := branch.TemplateBackportBranchName(cfg, fields)
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		log.Infof("Backport branch: %s", backportBranch)
+		log.Info("Dry run enabled, skipping backport")
+
+		return nil
+	}
+
+	remotes, err := resolveRepoRemotes(cfg.PR.PushRemote)
+	if err != nil {
+		return err
+	}
+
+	// Pull request refs only exist in the base repository
+	fetchSource := remotes.FetchSource()
+
+	targetSHA, err := fetchRef(fetchSource, opts.Target)
+	if err != nil {
+		return err
+	}
+
+	if opts.Continue {
+		if err := checkBackportResolved(backportBranch); err != nil {
+			return err
+		}
+	} else if err := cherryPickPR(fetchSource, originalPR, backportBranch, targetSHA, opts.Target); err != nil {
+		return err
+	}
+
+	if err := pushBranch(remotes.PushRemote, backportBranch); err != nil {
+		return err
+	}
+
+	commits, err := fetchBranchCommits(backportBranch, targetSHA)
+	if err != nil {
+		return err
+	}
+
+	prCfg := cfg.PR
+	prCfg.Title, prCfg.Body = cfg.Backport.Title, cfg.Backport.Body
+	answerChecklist := false
+	prCfg.AnswerChecklist = &answerChecklist
+
+	newPR, err := pr.TemplatePR(
+		models.Branch{Original: backportBranch, Fields: fields}, prCfg, opts.Confirm, cfg.Branch.TokenSeparators,
+		pr.NewIssueLinker(cfg, setupCfg), commits, func() (string, error) { return "", nil }, nil,
+	)
+	if err != nil {
+		return err
+	}
+	newPR.Labels = lo.Uniq(append(newPR.Labels, cfg.Backport.Labels...))
+
+	log.Debug(fmt.Sprintf("Pull request title: %s", newPR.Title))
+	log.Debug(fmt.Sprintf("Pull request body:\n\n%s", newPR.Body))
+
+	if !opts.Confirm {
+		submit, err := reviewNewPR(newPR)
+		if err != nil {
+			return err
+		}
+		if !submit {
+			log.Infof("Aborted, to open the pull request later run 'gh prx backport %d --to %s --continue'",
+				originalPR.Number, opts.Target)
+
+			return nil
+		}
+	}
+
+	if len(newPR.Labels) > 0 {
+		if newPR.Labels, err = prepareLabels(cfg.PR, newPR.Labels); err != nil {
+			return err
+		}
+	}
+
+	s := utils.StartSpinner("Creating pull request...", "Created pull request")
+	args := []string{
+		"pr", "create", "--title", newPR.Title, "--body", newPR.Body,
+		"--base", opts.Target, "--head", remotes.HeadRef(backportBranch),
+	}
+	if len(newPR.Labels) > 0 {
+		args = append(args, "--label", strings.Join(newPR.Labels, ","))
+	}
+	if remotes.IsFork() {
+		args = append(args, "--repo", remotes.BaseRepo)
+	}
+	stdOut, _, err := gh.Exec(args...)
+	s.Stop()
+	if err != nil {
+		return errors.Wrap(err, "Failed to create pull request")
+	}
+	log.Info(strings.TrimSpace(stdOut.String()))
+
+	return nil
+}
+
+// cherryPickPR creates the backport branch from the target branch and cherry-picks the pull request commits onto it.
+func cherryPickPR(
+	fetchSource string,
+	originalPR *backportedPR,
+	backportBranch string,
+	targetSHA string,
+	target string,
+) error {
+	if _, err := fetchRef(fetchSource, fmt.Sprintf("refs/pull/%d/head", originalPR.Number)); err != nil {
+		return err
+	}
+
+	// Merge commits (e.g. of the base branch into the pull request branch) are not backported
+	out, err := utils.Exec("git", append(
+		[]string{"rev-list", "--no-walk=unsorted", "--no-merges"},
+		lo.Map(originalPR.Commits, func(c prCommit, _ int) string { return c.OID })...,
+	)...)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to list the commits of pull request #%d", originalPR.Number)
+	}
+	shas := lo.Compact(strings.Split(out, "\n"))
+	if len(shas) == 0 {
+		return errors.Errorf("Pull request #%d has no commits to backport", originalPR.Number)
+	}
+
+	if _, err := utils.Exec("git", "checkout", "-b", backportBranch, targetSHA); err != nil {
+		return errors.Wrapf(err, "Failed to create branch '%s'", backportBranch)
+	}
+
+	s := utils.StartSpinner(
+		fmt.Sprintf("Cherry-picking %d commit(s) onto '%s'...", len(shas), target),
+		fmt.Sprintf("Cherry-picked %d commit(s) onto '%s'", len(shas), target),
+	)
+	_, err = utils.Exec("git", append([]string{"cherry-pick", "-x"}, shas...)...)
+	s.Stop()
+	if err != nil {
+		return errors.Wrapf(err, heredoc.Doc(`
+			Failed to cherry-pick pull request #%[1]d onto '%[2]s'.
+			If there are conflicts, resolve them, run 'git cherry-pick --continue'
+			and run 'gh prx backport %[1]d --to %[2]s --continue'.
+			To cancel, run 'git cherry-pick --abort'`), originalPR.Number, target)
+	}
+
+	return nil
+}
+
+// checkBackportResolved checks that the backport branch is checked out and isn't in the middle of a cherry-pick.
+func checkBackportResolved(backportBranch string) error {
+	current, err := fetchCurrentBranch()
+	if err != nil {
+		return err
+	}
+	if current != backportBranch {
+		return errors.Errorf("Backport branch '%s' is not checked out", backportBranch)
+	}
+
+	if _, err := utils.Exec("git", "rev-parse", "--quiet", "--verify", "CHERRY_PICK_HEAD"); err == nil {
+		return errors.New("A cherry-pick is in progress, resolve the conflicts and run 'git cherry-pick --continue' first")
+	}
+
+	return nil
+}
+
+// fetchRef fetches a ref from a remote (or a repository url) and returns its commit SHA.
+func fetchRef(source string, ref string) (string, error) {
+	s := utils.StartSpinner(fmt.Sprintf("Fetching '%s'...", ref), fmt.Sprintf("Fetched '%s'", ref))
+	_, err := utils.Exec("git", "fetch", source, ref)
+	s.Stop()
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to fetch '%s'", ref)
+	}
+
+	out, err := utils.Exec("git", "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to resolve '%s'", ref)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+func fetchBackportedPR(selector string) (*backportedPR, error) {
+	stdOut, _, err := gh.Exec(
+		"pr", "view", selector, "--json", "number,title,body,baseRefName,headRefName,state,url,commits",
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to fetch pull request '%s'", selector)
+	}
+
+	originalPR := &backportedPR{}
+	if err := json.Unmarshal(stdOut.Bytes(), originalPR); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse pull request")
+	}
+	originalPR.Body = strings.ReplaceAll(originalPR.Body, "\r\n", "\n")
+
+	return originalPR, nil
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/cli/go-gh"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/ilaif/gh-prx/pkg/branch"
+	"github.com/ilaif/gh-prx/pkg/changelog"
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+type ChangelogOpts struct {
+	From    string
+	To      string
+	Version string
+	Prepend bool
+}
+
+// mergedPR is a merged pull request, as listed by gh pr list.
+type mergedPR struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	HeadRefName string `json:"headRefName"`
+	MergedAt    string `json:"mergedAt"`
+	Author      struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	MergeCommit struct {
+		OID string `json:"oid"`
+	} `json:"mergeCommit"`
+}
+
+func NewChangelogCmd() *cobra.Command {
+	opts := &ChangelogOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Generate a changelog from the pull requests merged between two git refs.",
+		Long: heredoc.Docf(`
+			Generate a changelog from the pull requests merged between two git refs.
+
+			The merged pull requests whose merge commit is in the %[1]s<from>..<to>%[1]s range are grouped into the
+			%[1]schangelog.sections%[1]s of the config file, by their type and labels. The type is parsed from the
+			pull request branch name (according to %[1]sbranch.pattern%[1]s), or from its title
+			(e.g. %[1]sfeat(api): Add foo%[1]s).
+
+			The changelog is rendered with the %[1]schangelog.template%[1]s template and printed,
+			or prepended to %[1]schangelog.path%[1]s (default: %[1]sCHANGELOG.md%[1]s) with %[1]s--prepend%[1]s.
+		`, "`"),
+		Example: heredoc.Doc(`
+			$ gh prx changelog --from v1.2.0 # The changes since v1.2.0
+			$ gh prx changelog --from v1.2.0 --to v1.3.0 # The changes of v1.3.0
+			$ gh prx changelog --from v1.2.0 --version v1.3.0 --prepend # Prepend the changes to CHANGELOG.md
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return generateChangelogCmd(opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.StringVar(&opts.From, "from", "", "The `ref` (e.g. the previous release tag) to list changes from, exclusive")
+	fl.StringVar(&opts.To, "to", "HEAD", "The `ref` to list changes to, inclusive")
+	fl.StringVar(
+		&opts.Version,
+		"version",
+		"",
+		"The `version` to title the changes with (default: --to if it isn't HEAD, or \"Unreleased\")",
+	)
+	fl.BoolVar(&opts.Prepend, "prepend", false, "Prepend the changelog to the changelog file instead of printing it")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+func generateChangelogCmd(opts *ChangelogOpts) error {
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	version := opts.Version
+	if version == "" {
+		version = "Unreleased"
+		if opts.To != "HEAD" {
+			version = opts.To
+		}
+	}
+
+	entries, err := fetchChangelogEntries(cfg, opts.From, opts.To)
+	if err != nil {
+		return err
+	}
+
+	content, err := renderChangelog(cfg, opts.From, opts.To, version, entries)
+	if err != nil {
+		return err
+	}
+
+	if !opts.Prepend {
+		fmt.Print(content)
+
+		return nil
+	}
+
+	return prependChangelog(cfg.Changelog.Path, content)
+}
+
+// fetchChangelogEntries returns the changelog entries of the pull requests merged between two git refs.
+func fetchChangelogEntries(cfg *config.RepositoryConfig, from string, to string) ([]changelog.Entry, error) {
+	out, err := utils.Exec("git", "log", "--format=%H", from+".."+to)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to list the commits between '%s' and '%s'", from, to)
+	}
+	shas := lo.SliceToMap(lo.Compact(strings.Split(out, "\n")), func(sha string) (string, bool) { return sha, true })
+
+	out, err = utils.Exec("git", "log", "-1", "--format=%cI", from)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to resolve '%s'", from)
+	}
+
+	prs, err := fetchMergedPRs(strings.TrimSpace(out))
+	if err != nil {
+		return nil, err
+	}
+	// Pull requests merged outside of the range (e.g. after --to, or into another branch) are filtered out
+	prs = lo.Filter(prs, func(p mergedPR, _ int) bool { return shas[p.MergeCommit.OID] })
+	sort.SliceStable(prs, func(i, j int) bool { return prs[i].MergedAt < prs[j].MergedAt })
+
+	entries := lo.Map(prs, func(p mergedPR, _ int) changelog.Entry {
+		branchType := ""
+		if b, err := branch.ParseBranch(p.HeadRefName, cfg.Branch); err == nil {
+			branchType, _ = b.Fields["Type"].(string)
+		}
+		labels := []string{}
+		for _, l := range p.Labels {
+			labels = append(labels, l.Name)
+		}
+
+		return changelog.NewEntry(p.Number, p.Title, p.URL, p.Author.Login, labels, branchType)
+	})
+	log.Debugf("Found %d merged pull requests between '%s' and '%s'", len(entries), from, to)
+
+	return entries, nil
+}
+
+// renderChangelog renders the changelog of the pull requests merged between two git refs.
+func renderChangelog(
+	cfg *config.RepositoryConfig,
+	from string,
+	to string,
+	version string,
+	entries []changelog.Entry,
+) (string, error) {
+	out, err := utils.Exec("git", "log", "-1", "--format=%cs", to)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to resolve '%s'", to)
+	}
+
+	funcMaps, err := utils.GenerateTemplateFunctions(cfg.Branch.TokenSeparators)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate template functions")
+	}
+
+	return changelog.Render(cfg.Changelog.Template, funcMaps, changelog.Data{
+		Version:  version,
+		Date:     strings.TrimSpace(out),
+		From:     from,
+		To:       to,
+		Sections: changelog.Group(entries, cfg.Changelog.Sections, cfg.Changelog.ExcludeLabels),
+		Entries:  entries,
+	})
+}
+
+// mergedPRsLimit is the maximum number of pull requests GitHub's search returns.
+const mergedPRsLimit = 1000
+
+// fetchMergedPRs returns the pull requests merged since a date (ISO 8601).
+// Fails when the search limit is reached, since the changelog would silently miss pull requests.
+func fetchMergedPRs(since string) ([]mergedPR, error) {
+	s := utils.StartSpinner("Fetching merged pull requests...", "Fetched merged pull requests")
+	stdOut, _, err := gh.Exec(
+		"pr", "list", "--state", "merged", "--limit", fmt.Sprintf("%d", mergedPRsLimit), "--search", "merged:>="+since,
+		"--json", "number,title,url,headRefName,mergedAt,author,labels,mergeCommit",
+	)
+	s.Stop()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to fetch merged pull requests")
+	}
+
+	prs := []mergedPR{}
+	if err := json.Unmarshal(stdOut.Bytes(), &prs); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse merged pull requests")
+	}
+	if len(prs) >= mergedPRsLimit {
+		return nil, errors.Errorf(
+			"At least %d pull requests were merged since %s, the changelog would miss the ones GitHub's search "+
+				"doesn't return. Use a more recent starting ref",
+			mergedPRsLimit, since,
+		)
+	}
+
+	return prs, nil
+}
+
+// prependChangelog prepends a changelog to the changelog file, creating it at the repository root if it
+// doesn't exist.
+func prependChangelog(path string, content string) error {
+	fullPath, err := findOptionalPathInRepo(path)
+	if err != nil {
+		return err
+	}
+
+	existing := []byte{}
+	if fullPath != "" {
+		if existing, err = utils.ReadFile(fullPath); err != nil {
+			return err
+		}
+	} else {
+		out, err := utils.Exec("git", "rev-parse", "--show-toplevel")
+		if err != nil {
+			return errors.Wrap(err, "Failed to find the repository root")
+		}
+		fullPath = filepath.Join(strings.TrimSpace(out), path)
+	}
+
+	if err := utils.WriteFile(fullPath, []byte(changelog.Prepend(string(existing), content))); err != nil {
+		return err
+	}
+	log.Infof("Prepended changelog to '%s'", fullPath)
+
+	return nil
+}
@@ -19,7 +19,13 @@ import (
 	"github.com/ilaif/gh-prx/pkg/utils"
 )
 
+type CheckoutNewOpts struct {
+	Type string
+}
+
 func NewCheckoutNewCmd() *cobra.Command {
+	opts := &CheckoutNewOpts{}
+
 	cmd := &cobra.Command{
 		Use:   "checkout-new [issue-id]",
 		Short: "Create a new branch based on an issue and checkout to it.",
@@ -28,7 +34,7 @@ func NewCheckoutNewCmd() *cobra.Command {
 			Create a new branch based on an issue and checkout to it.
 
 			If the issue type ({{.Type}}) can't be resolved from the labels automatically,
-			the user will be prompted to choose a type.
+			the user will be prompted to choose a type, unless it's passed with %[1]s--type%[1]s.
 		`, "`"),
 		Example: heredoc.Doc(`
 			// Create a new branch based on a list of available issues and checkout to it:
@@ -36,6 +42,9 @@ func NewCheckoutNewCmd() *cobra.Command {
 
 			// Create a new branch based on issue 1234 and checkout to it:
 			$ gh prx checkout-new 1234
+
+			// Create a new branch based on issue 1234 with the feat type, without prompting:
+			$ gh prx checkout-new 1234 --type feat --no-prompt
 		`),
 		Aliases: []string{"switch-create", "sc", "cob"},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -46,14 +55,17 @@ func NewCheckoutNewCmd() *cobra.Command {
 				issueID = args[0]
 			}
 
-			return checkoutNew(ctx, issueID)
+			return checkoutNew(ctx, issueID, opts)
 		},
 	}
 
+	fl := cmd.Flags()
+	fl.StringVarP(&opts.Type, "type", "t", "", "The issue `type`, overriding the type resolved from its labels")
+
 	return cmd
 }
 
-func checkoutNew(ctx context.Context, id string) error {
+func checkoutNew(ctx context.Context, id string, opts *CheckoutNewOpts) error {
 	setupCfg, err := config.LoadSetupConfig()
 	if err != nil {
 		return err
@@ -70,6 +82,10 @@ func checkoutNew(ctx context.Context, id string) error {
 	}
 
 	if id == "" {
+		if !utils.CanPrompt() {
+			return errors.Wrap(utils.ErrCannotPrompt, "Pass the issue id as an argument")
+		}
+
 		id, err = chooseIssue(ctx, provider)
 		if err != nil {
 			return errors.Wrap(err, "Failed to choose issue")
@@ -83,6 +99,10 @@ func checkoutNew(ctx context.Context, id string) error {
 		return errors.Wrap(err, "Failed to get issue")
 	}
 
+	if opts.Type != "" {
+		issue.Type = opts.Type
+	}
+
 	branchName := issue.SuggestedBranchName
 
 	if branchName == "" {
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/caarlos0/log"
+	"github.com/cli/go-gh"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/codeowners"
+)
+
+// selectCodeownersReviewers suggests the CODEOWNERS of the changed files as reviewers, and returns the ones
+// selected by the user. The author and already requested reviewers are excluded.
+func selectCodeownersReviewers(changedFiles []string, requested []string, confirm bool) ([]string, error) {
+	rules, err := codeowners.Load()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Warn("No CODEOWNERS file found, skipping reviewers suggestion")
+
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	author, err := fetchCurrentUser()
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []string{}
+	for _, owner := range codeowners.Owners(rules, changedFiles) {
+		// Owners can also be emails, which can't be requested as reviewers
+		if !strings.HasPrefix(owner, "@") {
+			continue
+		}
+
+		owner = strings.TrimPrefix(owner, "@")
+		if strings.EqualFold(owner, author) || lo.Contains(requested, owner) {
+			continue
+		}
+
+		owners = append(owners, owner)
+	}
+
+	if len(owners) == 0 {
+		log.Info("No CODEOWNERS to request reviews from")
+
+		return nil, nil
+	}
+
+	if confirm {
+		log.Infof("Requesting reviews from CODEOWNERS: %s", strings.Join(owners, ", "))
+
+		return owners, nil
+	}
+
+	selected := []string{}
+	if err := survey.AskOne(&survey.MultiSelect{
+		Message: "Request reviews from CODEOWNERS:",
+		Options: owners,
+		Default: owners,
+	}, &selected); err != nil {
+		return nil, errors.Wrap(err, "Failed to ask for reviewers")
+	}
+
+	return selected, nil
+}
+
+func fetchCurrentUser() (string, error) {
+	stdOut, _, err := gh.Exec("api", "user", "--jq", ".login")
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to fetch current user")
+	}
+
+	return strings.TrimSpace(stdOut.String()), nil
+}
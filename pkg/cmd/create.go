@@ -4,19 +4,22 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/MakeNowJust/heredoc"
 	"github.com/caarlos0/log"
 	"github.com/cli/go-gh"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 
-	"github.com/ilaif/gh-prx/pkg/ai"
 	"github.com/ilaif/gh-prx/pkg/branch"
+	"github.com/ilaif/gh-prx/pkg/commit"
 	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
 	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/stack"
 	"github.com/ilaif/gh-prx/pkg/utils"
 )
 
@@ -26,6 +29,7 @@ type CreateOpts struct {
 	WebMode          bool
 	NoMaintainerEdit bool
 	RecoverFile      string
+	ResumeFile       string
 
 	IsDraft    bool
 	BaseBranch string
@@ -37,7 +41,12 @@ type CreateOpts struct {
 	Projects  []string
 	Milestone string
 
-	NoAISummary bool
+	CodeownersReviewers bool
+
+	AIOpts
+
+	IfExists string
+	Set      []string
 
 	DryRun bool
 }
@@ -58,12 +67,27 @@ func NewCreateCmd() *cobra.Command {
 
 			A pull request description (body) template can be defined in %[1]s.github/pull_request_template.md%[1]s.
 
+			Reviewers can be suggested from the CODEOWNERS of the changed files with %[1]s--codeowners-reviewers%[1]s,
+			or by default by setting %[1]spr.codeowners_reviewers: true%[1]s in the config file.
+
+			Before the pull request is created, you can submit it, edit its title and description in your editor
+			(%[1]s$VISUAL%[1]s, %[1]s$GIT_EDITOR%[1]s or %[1]s$EDITOR%[1]s), or abort. When aborted or when creation fails,
+			the pull request is saved to a file that can be resumed with %[1]s--resume%[1]s.
+
+			When a pull request already exists for the branch, you will be prompted to update it, open it in the browser
+			or abort. Use %[1]s--if-exists%[1]s to decide upfront (e.g. in scripts).
+
+			Template variables missing from the branch name (e.g. %[1]s{{.Issue}}%[1]s) are prompted for,
+			or can be set with %[1]s--set Key=Value%[1]s.
+
 			All of %[1]sgh pr create%[1]s flags are supported.
 		`, "`"),
 		Example: heredoc.Doc(`
 			$ gh prx create # Good defaults
 			$ gh prx create --web # Open the pull request in the browser before creating it
 			$ gh prx create --confirm # skip confirmation prompt for PR checklist questions
+			$ gh prx create --if-exists=update # update the pull request if it already exists
+			$ gh prx create --no-prompt --set Issue=ENG-123 # In CI or scripts, set the variables upfront
 		`),
 		Aliases: []string{"new"},
 		Args:    cobra.NoArgs,
@@ -85,6 +109,12 @@ func NewCreateCmd() *cobra.Command {
 	)
 	fl.BoolVarP(&opts.WebMode, "web", "w", false, "Open the web browser to create a pull request")
 	fl.StringSliceVarP(&opts.Reviewers, "reviewer", "r", nil, "Request reviews from people or teams by their `handle`")
+	fl.BoolVar(
+		&opts.CodeownersReviewers,
+		"codeowners-reviewers",
+		false,
+		"Suggest the CODEOWNERS of the changed files as reviewers (default: pr.codeowners_reviewers config)",
+	)
 	fl.StringSliceVarP(
 		&opts.Assignees,
 		"assignee",
@@ -96,14 +126,29 @@ func NewCreateCmd() *cobra.Command {
 	fl.StringSliceVarP(&opts.Projects, "project", "p", nil, "Add the pull request to projects by `name`")
 	fl.StringVarP(&opts.Milestone, "milestone", "m", "", "Add the pull request to a milestone by `name`")
 	fl.BoolVar(&opts.NoMaintainerEdit, "no-maintainer-edit", false, "Disable maintainer's ability to modify pull request")
-	fl.StringVar(&opts.RecoverFile, "recover", "", "Recover input from a failed run of create")
-	fl.BoolVar(&opts.NoAISummary, "no-ai-summary", false, "Disable AI-powered summary")
+	fl.StringVar(&opts.RecoverFile, "recover", "", "Recover input from a failed run of gh pr create")
+	fl.StringVar(&opts.ResumeFile, "resume", "", "Resume the pull request saved by a failed or aborted run of create")
+	cmd.MarkFlagsMutuallyExclusive("recover", "resume")
+	addAIFlags(fl, &opts.AIOpts)
+	fl.StringVar(
+		&opts.IfExists,
+		"if-exists",
+		"",
+		"What to do when a pull request already exists for the branch: {update|skip|fail} (default: prompt)",
+	)
+	fl.StringArrayVar(&opts.Set, "set", nil, "Set a template variable, overriding the branch fields: `Key=Value`")
 	fl.BoolVar(&opts.DryRun, "dry-run", false, "Print the pull request body and title without creating the pull request")
 
 	return cmd
 }
 
 func create(ctx context.Context, opts *CreateOpts) error { // nolint:cyclop
+	opts.Confirm = opts.Confirm || !utils.CanPrompt()
+
+	if opts.IfExists != "" && !lo.Contains([]string{"update", "skip", "fail"}, opts.IfExists) {
+		return errors.Errorf("Invalid --if-exists value '%s': should be one of update, skip or fail", opts.IfExists)
+	}
+
 	setupCfg, err := config.LoadSetupConfig()
 	if err != nil {
 		return err
@@ -114,148 +159,318 @@ func create(ctx context.Context, opts *CreateOpts) error { // nolint:cyclop
 		return err
 	}
 
-	log.Debug("Fetching current branch name")
-	gitDiffOutput, err := utils.Exec("git", "branch", "--show-current")
+	if opts.AITimeout > 0 {
+		cfg.AI.Timeout = opts.AITimeout
+	}
+
+	branchName, err := fetchCurrentBranch()
 	if err != nil {
 		return err
 	}
-	branchName := strings.Trim(gitDiffOutput, "\n")
 
 	b, err := branch.ParseBranch(branchName, cfg.Branch)
 	if err != nil {
 		return err
 	}
+	if err := setTemplateVariables(b.Fields, opts.Set); err != nil {
+		return err
+	}
+
+	remotes, err := resolveRepoRemotes(cfg.PR.PushRemote)
+	if err != nil {
+		return err
+	}
+
+	existingPR, err := fetchExistingPR(remotes, b.Original)
+	if err != nil {
+		return err
+	}
+	if existingPR != nil && existingPR.State == "OPEN" {
+		return handleExistingPR(ctx, setupCfg, cfg, remotes, existingPR, opts)
+	}
 
 	if *cfg.PR.PushToRemote {
-		s := utils.StartSpinner("Pushing current branch to remote...", "Pushed branch to remote")
-		gitDiffOutput, err = utils.Exec("git", "push", "--set-upstream", "origin", b.Original)
-		s.Stop()
-		if err != nil {
+		if err := pushBranch(remotes.PushRemote, b.Original); err != nil {
 			return err
 		}
-		log.Info(strings.Trim(gitDiffOutput, "\n"))
 	}
 
 	baseBranch := opts.BaseBranch
 	if baseBranch == "" {
-		s := utils.StartSpinner("Fetching repository default branch...", "Fetched repository default branch")
-		stdOut, _, err := gh.Exec("repo", "view", "--json", "defaultBranchRef", "--jq", ".defaultBranchRef.name")
-		s.Stop()
+		parent, err := stack.GetParent(b.Original)
 		if err != nil {
-			return errors.Wrap(err, "Failed to fetch default branch")
+			return err
 		}
-		baseBranch = strings.Trim(stdOut.String(), "\n")
+
+		if parent != "" {
+			log.Infof("Branch is stacked on '%s', using it as the base branch", parent)
+			baseBranch = parent
+		} else if baseBranch, err = fetchDefaultBranch(remotes.BaseRepo); err != nil {
+			return err
+		}
+	}
+
+	var newPR *models.PullRequest
+	if opts.ResumeFile != "" {
+		newPR, err = loadRecoveryFile(opts.ResumeFile)
+	} else {
+		newPR, err = renderNewPR(ctx, setupCfg, cfg, b, baseBranch, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Debug(fmt.Sprintf("Pull request title: %s", newPR.Title))
+	log.Debug(fmt.Sprintf("Pull request body:\n\n%s", newPR.Body))
+	log.Debug(fmt.Sprintf("Pull request labels: %v", newPR.Labels))
+
+	if opts.DryRun {
+		log.Info("Dry run enabled, skipping pull request creation")
+
+		return nil
 	}
 
-	if cfg.PullRequestTemplatePath != "" {
-		prTemplatePath, err := utils.FindRelativePathInRepo(cfg.PullRequestTemplatePath)
+	if !opts.Confirm {
+		submit, err := reviewNewPR(newPR)
 		if err != nil {
-			return errors.Wrap(err, "Failed to find pull request template path")
+			return err
 		}
-		prTemplateBytes, err := utils.ReadFile(prTemplatePath)
-		if err != nil {
-			return errors.Wrap(err, "Failed to read pull request template")
+		if !submit {
+			return abortNewPR(newPR)
 		}
-		cfg.PR.Body = string(prTemplateBytes)
 	}
 
-	out, err := utils.Exec("git", "log", "--pretty=format:%s", "--no-merges", b.Original, "^"+baseBranch)
-	if err != nil {
-		return errors.Wrap(err, "Failed to fetch branch commits")
+	if len(newPR.Labels) > 0 {
+		if newPR.Labels, err = prepareLabels(cfg.PR, newPR.Labels); err != nil {
+			return err
+		}
 	}
-	commits := strings.Split(out, "\n")
-	log.Debug(fmt.Sprintf("Commits:\n%s", strings.Join(commits, "\n")))
 
-	aiSummarizer := func() (string, error) {
-		if opts.NoAISummary || !ai.IsAISummarizerAvailable() {
-			log.Debug("AI-powered summary is disabled")
+	s := utils.StartSpinner("Creating pull request...", "Created pull request")
+	args := []string{"pr", "create", "--title", newPR.Title, "--body", newPR.Body, "--base", baseBranch}
+	args = append(args, generatePrCreateArgsFromOpts(opts, newPR)...)
+	if remotes.IsFork() {
+		args = append(args, "--repo", remotes.BaseRepo)
+		if opts.HeadBranch == "" {
+			args = append(args, "--head", remotes.HeadRef(b.Original))
+		}
+	}
+	stdOut, _, err := gh.Exec(args...)
+	s.Stop()
+	if err != nil {
+		recoveryFile, saveErr := saveRecoveryFile(newPR)
+		if saveErr != nil {
+			log.WithError(saveErr).Warn("Failed to save recovery file")
 
-			return "", nil
+			return errors.Wrap(err, "Failed to create pull request")
 		}
 
-		aiSummary, err := createAISummary(ctx, baseBranch, cfg.PR.Body, commits)
-		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
-				log.Warn("AI-powered summary timed out, skipping")
+		return errors.Wrapf(err, "Failed to create pull request, to retry run 'gh prx create --resume %s'", recoveryFile)
+	}
+	log.Info(strings.Trim(stdOut.String(), "\n"))
 
-				return "", nil
-			}
+	if opts.WebMode {
+		return nil
+	}
 
-			return "", err
-		}
+	return refreshStackNavigation(remotes, b.Original)
+}
 
-		return aiSummary, nil
+// renderNewPR renders the title and body of a new pull request, and adds the labels, reviewers, assignees and
+// projects matching the changed files.
+func renderNewPR(
+	ctx context.Context,
+	setupCfg *config.SetupConfig,
+	cfg *config.RepositoryConfig,
+	b models.Branch,
+	baseBranch string,
+	opts *CreateOpts,
+) (*models.PullRequest, error) {
+	if err := loadPRTemplate(cfg, b, opts.Confirm); err != nil {
+		return nil, err
 	}
 
-	pr, err := pr.TemplatePR(b, cfg.PR, opts.Confirm, cfg.Branch.TokenSeparators, commits, aiSummarizer)
+	commits, err := fetchBranchCommits(b.Original, baseBranch)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	log.Debug(fmt.Sprintf("Pull request title: %s", pr.Title))
-	log.Debug(fmt.Sprintf("Pull request body:\n\n%s", pr.Body))
-	log.Debug(fmt.Sprintf("Pull request labels: %v", pr.Labels))
+	aiSummarizer := newAISummarizer(ctx, setupCfg, cfg, b, baseBranch, commits, opts.AIOpts, opts.Confirm)
+	checklistAnswerer := newAIChecklistAnswerer(ctx, cfg, b, baseBranch, commits, opts.AIOpts)
 
-	if opts.DryRun {
-		log.Info("Dry run enabled, skipping pull request creation")
+	newPR, err := pr.TemplatePR(
+		b, cfg.PR, opts.Confirm, cfg.Branch.TokenSeparators, pr.NewIssueLinker(cfg, setupCfg),
+		commits, aiSummarizer, checklistAnswerer,
+	)
+	if err != nil {
+		return nil, err
+	}
 
-		return nil
+	changedFiles, err := fetchChangedFiles(b.Original, baseBranch)
+	if err != nil {
+		return nil, err
 	}
+	pr.ApplyPathRules(newPR, cfg.PR.PathRules, changedFiles)
 
-	if len(pr.Labels) > 0 {
-		if err := createLabels(pr.Labels); err != nil {
-			return err
+	if opts.CodeownersReviewers || *cfg.PR.CodeownersReviewers {
+		reviewers, err := selectCodeownersReviewers(changedFiles, append(opts.Reviewers, newPR.Reviewers...), opts.Confirm)
+		if err != nil {
+			return nil, err
 		}
+		newPR.Reviewers = lo.Uniq(append(newPR.Reviewers, reviewers...))
 	}
 
-	s := utils.StartSpinner("Creating pull request...", "Created pull request")
-	args := []string{"pr", "create", "--title", pr.Title, "--body", pr.Body, "--base", baseBranch}
-	args = append(args, generatePrCreateArgsFromOpts(opts, pr.Labels)...)
-	stdOut, _, err := gh.Exec(args...)
+	return newPR, nil
+}
+
+// handleExistingPR updates, opens or skips an already existing pull request, according to --if-exists
+// or the user's choice.
+func handleExistingPR(
+	ctx context.Context,
+	setupCfg *config.SetupConfig,
+	cfg *config.RepositoryConfig,
+	remotes *models.RepoRemotes,
+	existingPR *ExistingPR,
+	opts *CreateOpts,
+) error {
+	log.Infof("Pull request #%d already exists for branch '%s': %s",
+		existingPR.Number, existingPR.HeadRefName, existingPR.URL)
+
+	action := opts.IfExists
+	if action == "" {
+		if opts.Confirm {
+			action = "fail"
+		} else if err := survey.AskOne(&survey.Select{
+			Message: "What would you like to do?",
+			Options: []string{"update", "open", "abort"},
+			Description: func(value string, _ int) string {
+				return map[string]string{
+					"update": "Update the pull request title and description",
+					"open":   "Open the pull request in the browser",
+					"abort":  "Do nothing",
+				}[value]
+			},
+		}, &action, survey.WithValidator(survey.Required)); err != nil {
+			return errors.Wrap(err, "Failed to ask for existing pull request action")
+		}
+	}
+
+	switch action {
+	case "update":
+		return updatePR(ctx, setupCfg, cfg, remotes, existingPR, &UpdateOpts{
+			Confirm: opts.Confirm,
+			AIOpts:  opts.AIOpts,
+			Set:     opts.Set,
+			DryRun:  opts.DryRun,
+		})
+	case "open":
+		if _, _, err := gh.Exec("pr", "view", existingPR.URL, "--web"); err != nil {
+			return errors.Wrap(err, "Failed to open pull request in the browser")
+		}
+
+		return nil
+	case "skip", "abort":
+		log.Info("Skipping pull request creation")
+
+		return nil
+	case "fail":
+		return errors.Errorf("Pull request #%d already exists for branch '%s'", existingPR.Number, existingPR.HeadRefName)
+	default:
+		return errors.Errorf("Invalid action '%s'", action)
+	}
+}
+
+func fetchCurrentBranch() (string, error) {
+	log.Debug("Fetching current branch name")
+	out, err := utils.Exec("git", "branch", "--show-current")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Trim(out, "\n"), nil
+}
+
+func pushBranch(remote string, branchName string) error {
+	s := utils.StartSpinner(
+		fmt.Sprintf("Pushing current branch to '%s'...", remote), fmt.Sprintf("Pushed branch to '%s'", remote),
+	)
+	out, err := utils.Exec("git", "push", "--set-upstream", remote, branchName)
 	s.Stop()
 	if err != nil {
-		return errors.Wrap(err, "Failed to create pull request")
+		return err
 	}
-	log.Info(strings.Trim(stdOut.String(), "\n"))
+	log.Info(strings.Trim(out, "\n"))
 
 	return nil
 }
 
-func createAISummary(ctx context.Context,
-	baseBranch string,
-	prBody string,
-	commits []string,
-) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+func fetchBranchCommits(branchName string, baseBranch string) ([]models.Commit, error) {
+	out, err := utils.Exec(
+		"git", "log", "--pretty=format:%H%x1f%an%x1f%ae%x1f%s%x1f%b%x1e", "--no-merges", branchName, "^"+baseBranch,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to fetch branch commits")
+	}
 
-	s := utils.StartSpinner("Creating an AI-powered summary", "Finished summarizing")
-	defer s.Stop()
+	commits := []models.Commit{}
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 5)
+		if len(fields) != 5 {
+			continue
+		}
+
+		body := strings.TrimSpace(fields[4])
+		commits = append(commits, models.Commit{
+			SHA:         fields[0],
+			Author:      fields[1],
+			AuthorEmail: fields[2],
+			Subject:     fields[3],
+			Body:        body,
+			Trailers:    commit.ParseTrailers(body),
+		})
+	}
+	log.Debug(fmt.Sprintf("Commits:\n%s", strings.Join(models.CommitSubjects(commits), "\n")))
 
-	gitDiffCmd := heredoc.Docf(`
-			git diff %[1]s --stat=10000 |
-			grep '|' |
-			awk '{ if ($3 > 10) print $1 }' |
-			xargs git diff ^%[1]s --ignore-all-space --ignore-blank-lines --ignore-space-change --unified=0 --word-diff --
-		`, baseBranch)
-	gitDiffOutput, err := utils.Exec("sh", "-c", gitDiffCmd)
+	return commits, nil
+}
+
+func fetchChangedFiles(branchName string, baseBranch string) ([]string, error) {
+	out, err := utils.Exec("git", "diff", "--name-only", baseBranch+"..."+branchName)
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to fetch branch commits")
+		return nil, errors.Wrap(err, "Failed to fetch branch changed files")
 	}
 
-	aiSummary, err := ai.SummarizeGitDiffOutput(ctx, gitDiffOutput, prBody)
+	return lo.Compact(strings.Split(out, "\n")), nil
+}
+
+// fetchDefaultBranch returns the default branch of a repository, or of the current repository if repo is empty.
+func fetchDefaultBranch(repo string) (string, error) {
+	s := utils.StartSpinner("Fetching repository default branch...", "Fetched repository default branch")
+	args := []string{"repo", "view"}
+	if repo != "" {
+		args = append(args, repo)
+	}
+	args = append(args, "--json", "defaultBranchRef", "--jq", ".defaultBranchRef.name")
+	stdOut, _, err := gh.Exec(args...)
+	s.Stop()
 	if err != nil {
-		log.Debug("Failed to summarize git diff output, falling back to file and commit diff")
-		aiSummary, err = ai.SummarizeGitDiffOutput(ctx, strings.Join(commits, "\n"), prBody)
-		if err != nil {
-			return "", err
-		}
+		return "", errors.Wrap(err, "Failed to fetch default branch")
 	}
 
-	return aiSummary, nil
+	return strings.Trim(stdOut.String(), "\n"), nil
 }
 
-func createLabels(labels []string) error {
+// prepareLabels creates the labels that don't exist yet, applying their configured definitions.
+// If creating labels is disabled, labels that don't exist are dropped instead.
+func prepareLabels(prCfg config.PullRequestConfig, labels []string) ([]string, error) {
+	if *prCfg.CreateLabels {
+		return labels, createLabels(labels, prCfg.Labels)
+	}
+
+	return filterExistingLabels(labels)
+}
+
+func createLabels(labels []string, definitions map[string]config.LabelConfig) error {
 	s := utils.StartSpinner("Creating labels (if not exist)...", "Created labels")
 	defer s.Stop()
 
@@ -263,7 +478,7 @@ func createLabels(labels []string) error {
 	for _, label := range labels {
 		label := label
 		g.Go(func() error {
-			_, stdErr, err := gh.Exec("label", "create", label)
+			_, stdErr, err := gh.Exec(pr.LabelCreateArgs(label, definitions)...)
 			if err != nil {
 				if !strings.Contains(stdErr.String(), "already exists") {
 					return errors.Wrapf(err, "Failed to create label '%s'", label)
@@ -283,23 +498,34 @@ func createLabels(labels []string) error {
 	return nil
 }
 
-func generatePrCreateArgsFromOpts(opts *CreateOpts, labels []string) []string {
+func filterExistingLabels(labels []string) ([]string, error) {
+	s := utils.StartSpinner("Fetching repository labels...", "Fetched repository labels")
+	stdOut, _, err := gh.Exec("label", "list", "--limit", "1000", "--json", "name", "--jq", ".[].name")
+	s.Stop()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to fetch repository labels")
+	}
+
+	return pr.FilterExistingLabels(labels, strings.Split(stdOut.String(), "\n")), nil
+}
+
+func generatePrCreateArgsFromOpts(opts *CreateOpts, newPR *models.PullRequest) []string {
 	args := []string{}
 
-	if len(opts.Assignees) > 0 {
-		args = append(args, "--assignee", strings.Join(opts.Assignees, ","))
+	if assignees := lo.Uniq(append(opts.Assignees, newPR.Assignees...)); len(assignees) > 0 {
+		args = append(args, "--assignee", strings.Join(assignees, ","))
 	}
-	if len(opts.Labels) > 0 || len(labels) > 0 {
-		args = append(args, "--label", strings.Join(append(opts.Labels, labels...), ","))
+	if labels := lo.Uniq(append(opts.Labels, newPR.Labels...)); len(labels) > 0 {
+		args = append(args, "--label", strings.Join(labels, ","))
 	}
-	if len(opts.Projects) > 0 {
-		args = append(args, "--project", strings.Join(opts.Projects, ","))
+	if projects := lo.Uniq(append(opts.Projects, newPR.Projects...)); len(projects) > 0 {
+		args = append(args, "--project", strings.Join(projects, ","))
 	}
 	if opts.Milestone != "" {
 		args = append(args, "--milestone", opts.Milestone)
 	}
-	if len(opts.Reviewers) > 0 {
-		args = append(args, "--reviewer", strings.Join(opts.Reviewers, ","))
+	if reviewers := lo.Uniq(append(opts.Reviewers, newPR.Reviewers...)); len(reviewers) > 0 {
+		args = append(args, "--reviewer", strings.Join(reviewers, ","))
 	}
 	if opts.IsDraft {
 		args = append(args, "--draft")
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/caarlos0/log"
+	"github.com/pkg/errors"
+
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+// recoveryState is the pull request saved when creating it is aborted or fails, resumed with create --resume.
+type recoveryState struct {
+	Title     string
+	Body      string
+	Labels    []string
+	Reviewers []string
+	Assignees []string
+	Projects  []string
+}
+
+// reviewNewPR lets the user submit, edit or abort the pull request. Returns whether to submit it.
+func reviewNewPR(newPR *models.PullRequest) (bool, error) {
+	editor := utils.Editor()
+	for {
+		fmt.Printf("\n%s\n\n%s\n\n", newPR.Title, newPR.Body)
+
+		action := ""
+		if err := survey.AskOne(&survey.Select{
+			Message: "What's next?",
+			Options: []string{"Submit", fmt.Sprintf("Edit (%s)", editor), "Abort"},
+		}, &action, survey.WithValidator(survey.Required)); err != nil {
+			return false, errors.Wrap(err, "Failed to ask for next action")
+		}
+
+		switch action {
+		case "Submit":
+			return true, nil
+		case "Abort":
+			return false, nil
+		}
+
+		edited, err := utils.EditString(pr.FormatForEdit(newPR), "PR-*.md")
+		if err != nil {
+			return false, errors.Wrap(err, "Failed to edit pull request")
+		}
+
+		title, body, err := pr.ParseEdited(edited)
+		if err != nil {
+			log.WithError(err).Warn("Discarding edit")
+
+			continue
+		}
+		newPR.Title, newPR.Body = title, body
+	}
+}
+
+// abortNewPR saves the aborted pull request to a recovery file, so it can be resumed.
+func abortNewPR(newPR *models.PullRequest) error {
+	recoveryFile, err := saveRecoveryFile(newPR)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Aborted, to resume run 'gh prx create --resume %s'", recoveryFile)
+
+	return nil
+}
+
+func saveRecoveryFile(newPR *models.PullRequest) (string, error) {
+	content, err := json.Marshal(recoveryState{
+		Title:     newPR.Title,
+		Body:      newPR.Body,
+		Labels:    newPR.Labels,
+		Reviewers: newPR.Reviewers,
+		Assignees: newPR.Assignees,
+		Projects:  newPR.Projects,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to marshal recovery file")
+	}
+
+	f, err := os.CreateTemp(os.TempDir(), "gh-prx-recover-*.json")
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create recovery file")
+	}
+	defer f.Close()
+
+	if _, err := f.Write(content); err != nil {
+		return "", errors.Wrap(err, "Failed to write recovery file")
+	}
+
+	return f.Name(), nil
+}
+
+func loadRecoveryFile(path string) (*models.PullRequest, error) {
+	content, err := utils.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := recoveryState{}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse recovery file")
+	}
+
+	return &models.PullRequest{
+		Title:     state.Title,
+		Body:      state.Body,
+		Labels:    state.Labels,
+		Reviewers: state.Reviewers,
+		Assignees: state.Assignees,
+		Projects:  state.Projects,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/ilaif/gh-prx/pkg/hooks"
+)
+
+type HooksInstallOpts struct {
+	Force bool
+}
+
+func NewHooksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Manage the git hooks that lint branch names and commit messages.",
+		Long: heredoc.Docf(`
+			Manage the git hooks that lint branch names and commit messages.
+
+			- %[1]spre-push%[1]s runs %[1]sgh prx lint branch%[1]s on the pushed branches.
+			- %[1]scommit-msg%[1]s runs %[1]sgh prx lint commit%[1]s on the commit message.
+
+			The hooks can be bypassed with %[1]s--no-verify%[1]s (e.g. %[1]sgit push --no-verify%[1]s).
+		`, "`"),
+	}
+
+	cmd.AddCommand(
+		NewHooksInstallCmd(),
+		NewHooksUninstallCmd(),
+	)
+
+	return cmd
+}
+
+func NewHooksInstallCmd() *cobra.Command {
+	opts := &HooksInstallOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the pre-push and commit-msg git hooks in the current repository.",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return hooksInstall(opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.BoolVarP(&opts.Force, "force", "f", false, "Overwrite existing hooks that weren't installed by gh prx")
+
+	return cmd
+}
+
+func NewHooksUninstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the git hooks installed by gh prx from the current repository.",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return hooksUninstall()
+		},
+	}
+}
+
+func hooksInstall(opts *HooksInstallOpts) error {
+	hooksDir, err := hooks.Dir(".")
+	if err != nil {
+		return err
+	}
+
+	return hooks.Install(hooksDir, opts.Force)
+}
+
+func hooksUninstall() error {
+	hooksDir, err := hooks.Dir(".")
+	if err != nil {
+		return err
+	}
+
+	return hooks.Uninstall(hooksDir)
+}
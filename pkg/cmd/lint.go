@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/ilaif/gh-prx/pkg/branch"
+	"github.com/ilaif/gh-prx/pkg/commit"
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+// ErrLintFailed is returned when linting found violations, after they were reported.
+var ErrLintFailed = errors.New("Lint failed")
+
+type LintCommitOpts struct {
+	Range string
+}
+
+type LintPROpts struct {
+	TitlePattern string
+}
+
+func NewLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Lint branch names, commit messages and pull requests against the repository conventions.",
+		Long: heredoc.Docf(`
+			Lint branch names, commit messages and pull requests against the repository conventions.
+
+			The lint commands exit with status 1 when they find violations, so they can be used in CI
+			and in git hooks (see %[1]sgh prx hooks install%[1]s).
+			In GitHub Actions, violations are reported as error annotations.
+		`, "`"),
+		Example: heredoc.Doc(`
+			// Lint the current branch name:
+			$ gh prx lint branch
+
+			// Lint the commit messages of a pull request in CI:
+			$ gh prx lint commit --range origin/main..HEAD
+
+			// Lint the title and checklist of pull request #123:
+			$ gh prx lint pr 123
+		`),
+	}
+
+	cmd.AddCommand(
+		NewLintBranchCmd(),
+		NewLintCommitCmd(),
+		NewLintPRCmd(),
+	)
+
+	return cmd
+}
+
+func NewLintBranchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "branch [<name>]",
+		Short: "Lint a branch name against the branch pattern.",
+		Long: heredoc.Docf(`
+			Lint a branch name against the branch pattern.
+
+			Without an argument, the current branch name is linted. The name should match %[1]sbranch.pattern%[1]s,
+			with its variables matching %[1]sbranch.variable_patterns%[1]s. On failure, the part of the pattern
+			that doesn't match is pointed out.
+
+			Branches matching %[1]slint.ignore_branches%[1]s (default: main, master, develop and backport branches)
+			are not linted.
+		`, "`"),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+
+			return lintBranch(name)
+		},
+	}
+}
+
+func NewLintCommitCmd() *cobra.Command {
+	opts := &LintCommitOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "commit [<file> | -]",
+		Short: "Lint commit messages against the Conventional Commits specification.",
+		Long: heredoc.Docf(`
+			Lint commit messages against the Conventional Commits specification.
+
+			The commit subject should look like %[1]s<type>[(<scope>)][!]: <description>%[1]s,
+			where the type is one of %[1]sissue.types%[1]s in the config file.
+			Merge, revert, fixup and squash commits, and commits matching %[1]spr.ignore_commits_patterns%[1]s,
+			are not linted.
+
+			The message is read from a file (e.g. the one git passes to the commit-msg hook), from the standard input
+			with %[1]s-%[1]s, or from the commits in %[1]s--range%[1]s. Without any, the HEAD commit is linted.
+		`, "`"),
+		Example: heredoc.Doc(`
+			$ gh prx lint commit .git/COMMIT_EDITMSG
+			$ echo "feat: Add foo" | gh prx lint commit -
+			$ gh prx lint commit --range origin/main..HEAD
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			source := ""
+			if len(args) > 0 {
+				source = args[0]
+			}
+
+			return lintCommit(source, opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.StringVar(&opts.Range, "range", "", "Lint the commits in a git revision `range` (e.g. origin/main..HEAD)")
+
+	return cmd
+}
+
+func NewLintPRCmd() *cobra.Command {
+	opts := &LintPROpts{}
+
+	cmd := &cobra.Command{
+		Use:   "pr [<number> | <url> | <branch>]",
+		Short: "Lint a pull request title and checklist.",
+		Long: heredoc.Docf(`
+			Lint a pull request title and checklist.
+
+			Without an argument, the pull request of the current branch is linted. In GitHub Actions, the pull request
+			that triggered the workflow is linted (from %[1]sGITHUB_REF%[1]s or %[1]sGITHUB_HEAD_REF%[1]s).
+
+			The title should match %[1]slint.title_pattern%[1]s, or by default a pattern derived from the
+			%[1]spr.title%[1]s template: its text is matched as is, its variables (e.g. %[1]s{{.Type}}%[1]s)
+			by %[1]sbranch.variable_patterns%[1]s, and its %[1]s{{with}}%[1]s and %[1]s{{if}}%[1]s blocks are optional.
+
+			The checklist items marked with %[1]s<!-- required -->%[1]s should be checked.
+		`, "`"),
+		Example: heredoc.Doc(`
+			$ gh prx lint pr 123
+			$ gh prx lint pr 123 --title-pattern '^(feat|fix): .+$'
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			selector := ""
+			if len(args) > 0 {
+				selector = args[0]
+			}
+
+			return lintPR(selector, opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.StringVar(
+		&opts.TitlePattern,
+		"title-pattern",
+		"",
+		"The regular expression the title should match (default: lint.title_pattern, or derived from pr.title)",
+	)
+
+	return cmd
+}
+
+func lintBranch(name string) error {
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		if name, err = fetchCurrentBranch(); err != nil {
+			return err
+		}
+		if name == "" {
+			return errors.New("No branch is checked out, pass the branch name to lint")
+		}
+	}
+
+	if _, ignored := lo.Find(cfg.Lint.IgnoreBranches, func(p string) bool { return utils.MatchGlob(p, name) }); ignored {
+		log.Infof("Branch '%s' is ignored", name)
+
+		return nil
+	}
+
+	if err := branch.Lint(name, cfg.Branch); err != nil {
+		lintErr := &branch.LintError{}
+		if !errors.As(err, &lintErr) {
+			return err
+		}
+
+		return reportLintErrors("Branch name", []error{lintErr})
+	}
+	log.Infof("Branch name '%s' is valid", name)
+
+	return nil
+}
+
+func lintCommit(source string, opts *LintCommitOpts) error {
+	if source != "" && opts.Range != "" {
+		return errors.New("Pass either a commit message file or --range, not both")
+	}
+
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	subjects, err := fetchCommitSubjects(source, opts.Range)
+	if err != nil {
+		return err
+	}
+
+	ignoreCommitsMatcher, err := regexp.Compile(strings.Join(cfg.PR.IgnoreCommitsPatterns, "|"))
+	if err != nil {
+		return errors.Wrap(err, "Failed to compile ignore commits matcher")
+	}
+
+	lintErrs := []error{}
+	for _, subject := range subjects {
+		if subject != "" && ignoreCommitsMatcher.MatchString(subject) {
+			log.Debugf("Commit '%s' is ignored", subject)
+
+			continue
+		}
+		if err := commit.Lint(subject, cfg.Issue.Types); err != nil {
+			lintErrs = append(lintErrs, err)
+		}
+	}
+	if len(lintErrs) > 0 {
+		return reportLintErrors("Commit message", lintErrs)
+	}
+	log.Infof("%d commit message(s) are valid", len(subjects))
+
+	return nil
+}
+
+func lintPR(selector string, opts *LintPROpts) error {
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	titlePattern := lo.Ternary(opts.TitlePattern != "", opts.TitlePattern, cfg.Lint.TitlePattern)
+	if titlePattern == "" {
+		funcMaps, err := utils.GenerateTemplateFunctions(cfg.Branch.TokenSeparators)
+		if err != nil {
+			return errors.Wrap(err, "Failed to generate template functions")
+		}
+		funcMaps = lo.Assign(funcMaps, pr.NewIssueLinker(cfg, setupCfg).TemplateFunctions())
+
+		if titlePattern, err = pr.TitlePattern(cfg.PR.Title, cfg.Branch.VariablePatterns, funcMaps); err != nil {
+			return err
+		}
+		log.Debugf("Title pattern derived from the pr title template: %s", titlePattern)
+	}
+
+	remotes, err := resolveRepoRemotes(cfg.PR.PushRemote)
+	if err != nil {
+		return err
+	}
+
+	if selector == "" {
+		selector = utils.GitHubActionsPR()
+	}
+	var lintedPR *ExistingPR
+	if selector != "" {
+		lintedPR, err = fetchRepoPR(remotes.BaseRepo, selector)
+	} else {
+		if selector, err = fetchCurrentBranch(); err != nil {
+			return err
+		}
+		if selector == "" {
+			return errors.New("No branch is checked out, pass the pull request to lint")
+		}
+		lintedPR, err = fetchExistingPR(remotes, selector)
+	}
+	if err != nil {
+		return err
+	}
+	if lintedPR == nil {
+		return errors.Errorf("No pull request found for '%s'", selector)
+	}
+
+	lintErrs := []error{}
+	if err := pr.LintTitle(lintedPR.Title, titlePattern); err != nil {
+		lintErrs = append(lintErrs, err)
+	}
+	for _, item := range pr.UncheckedRequiredItems(lintedPR.Body) {
+		lintErrs = append(lintErrs, errors.Errorf("Required checklist item '%s' is not checked", item))
+	}
+	if len(lintErrs) > 0 {
+		return reportLintErrors(fmt.Sprintf("Pull request #%d", lintedPR.Number), lintErrs)
+	}
+	log.Infof("Pull request #%d is valid", lintedPR.Number)
+
+	return nil
+}
+
+// fetchCommitSubjects returns the commit subjects to lint, from a commit message file, the standard input ("-"),
+// the commits in a revision range, or the HEAD commit.
+func fetchCommitSubjects(source string, revRange string) ([]string, error) {
+	switch {
+	case source == "-":
+		message, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to read commit message from standard input")
+		}
+
+		return []string{commit.Subject(string(message))}, nil
+	case source != "":
+		message, err := utils.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{commit.Subject(string(message))}, nil
+	case revRange != "":
+		out, err := utils.Exec("git", "log", "--format=%s", revRange)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to list the commits in '%s'", revRange)
+		}
+
+		return lo.Compact(strings.Split(out, "\n")), nil
+	default:
+		out, err := utils.Exec("git", "log", "-1", "--format=%s")
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to fetch the HEAD commit")
+		}
+
+		return []string{strings.TrimSpace(out)}, nil
+	}
+}
+
+// reportLintErrors prints lint violations of a subject (e.g. "Branch name") and returns ErrLintFailed.
+// In GitHub Actions, the violations are printed as error annotations.
+func reportLintErrors(subject string, lintErrs []error) error {
+	for _, err := range lintErrs {
+		if utils.IsGitHubActions() {
+			fmt.Println(utils.GitHubActionsAnnotation("error", subject, err.Error()))
+		} else {
+			fmt.Fprintf(os.Stderr, "✗ %s\n", err)
+		}
+	}
+
+	return errors.Wrapf(ErrLintFailed, "Found %d violation(s)", len(lintErrs))
+}
@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/cli/go-gh"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/ilaif/gh-prx/pkg/branch"
+	"github.com/ilaif/gh-prx/pkg/commit"
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+type MergeOpts struct {
+	Confirm    bool
+	Squash     bool
+	Merge      bool
+	Rebase     bool
+	Auto       bool
+	KeepBranch bool
+
+	DryRun bool
+}
+
+// mergeablePR is a pull request with the fields needed to render its merge commit message.
+type mergeablePR struct {
+	ExistingPR
+	HeadRefOID          string `json:"headRefOid"`
+	IsCrossRepository   bool   `json:"isCrossRepository"`
+	HeadRepositoryOwner struct {
+		Login string `json:"login"`
+	} `json:"headRepositoryOwner"`
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Commits []struct {
+		OID             string `json:"oid"`
+		MessageHeadline string `json:"messageHeadline"`
+		MessageBody     string `json:"messageBody"`
+		Authors         []struct {
+			Login string `json:"login"`
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"authors"`
+	} `json:"commits"`
+}
+
+func NewMergeCmd() *cobra.Command {
+	opts := &MergeOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "merge [<number> | <url> | <branch>]",
+		Short: "Merge a pull request on GitHub, extended.",
+		Long: heredoc.Docf(`
+			Merge a pull request on GitHub, extended.
+
+			Without an argument, the pull request of the current branch is merged.
+
+			The merge commit title and body are rendered from the %[1]smerge.title%[1]s and %[1]smerge.body%[1]s
+			templates in the config file, with access to the pull request title and number, the branch fields,
+			the commits (also grouped by Conventional Commits type), the referenced issues and the co-authors.
+			The merge method is set by %[1]smerge.method%[1]s (default: squash).
+
+			After merging, the local and remote branch are deleted, and if the merged branch was checked out,
+			the default branch is checked out and pulled. This can be disabled with %[1]s--keep-branch%[1]s or by setting
+			%[1]smerge.delete_branch: false%[1]s in the config file.
+
+			With %[1]s--auto%[1]s (or %[1]smerge.auto_merge: true%[1]s), auto-merge is enabled instead, and the pull request
+			is merged once its requirements are met.
+		`, "`"),
+		Example: heredoc.Doc(`
+			$ gh prx merge # Merge the pull request of the current branch
+			$ gh prx merge 123 --rebase # Rebase and merge pull request #123
+			$ gh prx merge --auto # Merge the pull request once its requirements are met
+			$ gh prx merge --dry-run # Print the merge commit title and body without merging
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selector := ""
+			if len(args) > 0 {
+				selector = args[0]
+			}
+
+			return merge(cmd.Context(), selector, opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.BoolVarP(&opts.Confirm, "confirm", "y", false, "Don't ask for user input")
+	fl.BoolVarP(&opts.Squash, "squash", "s", false, "Squash the commits into one commit and merge it into the base branch")
+	fl.BoolVarP(&opts.Merge, "merge", "m", false, "Merge the commits with the base branch")
+	fl.BoolVarP(&opts.Rebase, "rebase", "r", false, "Rebase the commits onto the base branch")
+	cmd.MarkFlagsMutuallyExclusive("squash", "merge", "rebase")
+	fl.BoolVar(&opts.Auto, "auto", false, "Automatically merge only after necessary requirements are met")
+	fl.BoolVar(&opts.KeepBranch, "keep-branch", false, "Don't delete the local and remote branch after merge")
+	fl.BoolVar(&opts.DryRun, "dry-run", false, "Print the merge commit title and body without merging the pull request")
+
+	return cmd
+}
+
+func merge(_ context.Context, selector string, opts *MergeOpts) error { // nolint:cyclop
+	if !opts.Confirm && !opts.DryRun && !utils.CanPrompt() {
+		return errors.Wrap(utils.ErrCannotPrompt, "Pass --confirm (-y) to merge the pull request without confirmation")
+	}
+
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	mergedPR, err := fetchMergeablePR(selector)
+	if err != nil {
+		return err
+	}
+	if mergedPR.State != "OPEN" {
+		return errors.Errorf("Pull request #%d is %s", mergedPR.Number, strings.ToLower(mergedPR.State))
+	}
+
+	method := cfg.Merge.Method
+	switch {
+	case opts.Squash:
+		method = "squash"
+	case opts.Merge:
+		method = "merge"
+	case opts.Rebase:
+		method = "rebase"
+	}
+
+	args := []string{"pr", "merge", fmt.Sprintf("%d", mergedPR.Number), "--" + method}
+	if method != "rebase" {
+		title, body, err := renderMergeCommit(setupCfg, cfg, mergedPR)
+		if err != nil {
+			return err
+		}
+
+		log.Debug(fmt.Sprintf("Merge commit title: %s", title))
+		log.Debug(fmt.Sprintf("Merge commit body:\n\n%s", body))
+
+		if opts.DryRun {
+			fmt.Printf("%s\n\n%s\n", title, body)
+			log.Info("Dry run enabled, skipping pull request merge")
+
+			return nil
+		}
+
+		if !opts.Confirm {
+			mergeCommit := &models.PullRequest{Title: title, Body: body}
+			submit, err := reviewNewPR(mergeCommit)
+			if err != nil {
+				return err
+			}
+			if !submit {
+				log.Info("Aborted")
+
+				return nil
+			}
+			title, body = mergeCommit.Title, mergeCommit.Body
+		}
+
+		args = append(args, "--subject", title, "--body", body)
+	} else if opts.DryRun {
+		log.Info("Dry run enabled, skipping pull request merge")
+
+		return nil
+	} else if !opts.Confirm {
+		proceed := false
+		if err := survey.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Rebase and merge pull request #%d?", mergedPR.Number),
+			Default: true,
+		}, &proceed); err != nil {
+			return errors.Wrap(err, "Failed to confirm merge")
+		}
+		if !proceed {
+			log.Info("Aborted")
+
+			return nil
+		}
+	}
+
+	auto := opts.Auto || *cfg.Merge.AutoMerge
+	if auto {
+		args = append(args, "--auto")
+	}
+
+	s := utils.StartSpinner(
+		fmt.Sprintf("Merging pull request #%d...", mergedPR.Number), fmt.Sprintf("Merged pull request #%d", mergedPR.Number),
+	)
+	_, _, err = gh.Exec(args...)
+	s.Stop()
+	if err != nil {
+		return errors.Wrapf(err, "Failed to merge pull request #%d", mergedPR.Number)
+	}
+
+	if auto {
+		// Auto-merge merges right away if the requirements are already met
+		state, err := fetchPRState(mergedPR.Number)
+		if err != nil {
+			return err
+		}
+		if state != "MERGED" {
+			log.Infof("Auto-merge enabled, pull request #%d will be merged once its requirements are met", mergedPR.Number)
+
+			return nil
+		}
+	}
+	log.Info(mergedPR.URL)
+
+	if opts.KeepBranch || !*cfg.Merge.DeleteBranch {
+		return nil
+	}
+	if mergedPR.IsCrossRepository {
+		log.Infof("Pull request #%d is from the fork of '%s', keeping its branch",
+			mergedPR.Number, mergedPR.HeadRepositoryOwner.Login,
+		)
+
+		return nil
+	}
+
+	return deleteMergedBranch(cfg.PR.PushRemote, mergedPR.HeadRefName, mergedPR.HeadRefOID)
+}
+
+// renderMergeCommit renders the merge commit title and body of a pull request from the merge config templates.
+func renderMergeCommit(
+	setupCfg *config.SetupConfig,
+	cfg *config.RepositoryConfig,
+	mergedPR *mergeablePR,
+) (string, string, error) {
+	fields := map[string]any{}
+	if b, err := branch.ParseBranch(mergedPR.HeadRefName, cfg.Branch); err != nil {
+		log.WithError(err).Debug("Failed to parse branch name, branch fields are unavailable in merge templates")
+	} else {
+		fields = b.Fields
+	}
+	fields = lo.Assign(fields, map[string]any{
+		"Title":  mergedPR.Title,
+		"Number": mergedPR.Number,
+		"URL":    mergedPR.URL,
+	})
+
+	commits := []models.Commit{}
+	coAuthors := []string{}
+	for _, c := range mergedPR.Commits {
+		newCommit := models.Commit{
+			SHA:      c.OID,
+			Subject:  c.MessageHeadline,
+			Body:     c.MessageBody,
+			Trailers: commit.ParseTrailers(c.MessageBody),
+		}
+		if len(c.Authors) > 0 {
+			newCommit.Author, newCommit.AuthorEmail = c.Authors[0].Name, c.Authors[0].Email
+		}
+		commits = append(commits, newCommit)
+
+		// Like GitHub, credit the commit authors other than the pull request author
+		for _, author := range c.Authors {
+			if author.Login != mergedPR.Author.Login && author.Email != "" {
+				coAuthors = append(coAuthors, fmt.Sprintf("%s <%s>", author.Name, author.Email))
+			}
+		}
+	}
+
+	// The pull request commits are ordered from oldest to newest, unlike git log
+	issueLinker := pr.NewIssueLinker(cfg, setupCfg)
+
+	return pr.TemplateMergeCommit(
+		cfg.Merge, cfg.PR, cfg.Branch.TokenSeparators, issueLinker, fields, lo.Reverse(commits), coAuthors,
+	)
+}
+
+// fetchMergeablePR returns a pull request by number, url or branch, or the pull request of the current branch
+// if selector is empty.
+func fetchMergeablePR(selector string) (*mergeablePR, error) {
+	args := []string{"pr", "view"}
+	if selector != "" {
+		args = append(args, selector)
+	}
+	args = append(args, "--json", "number,title,body,baseRefName,headRefName,headRefOid,isCrossRepository,"+
+		"headRepositoryOwner,state,url,author,commits")
+
+	stdOut, _, err := gh.Exec(args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to fetch pull request")
+	}
+
+	mergedPR := &mergeablePR{}
+	if err := json.Unmarshal(stdOut.Bytes(), mergedPR); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse pull request")
+	}
+
+	return mergedPR, nil
+}
+
+func fetchPRState(number int) (string, error) {
+	stdOut, _, err := gh.Exec("pr", "view", fmt.Sprintf("%d", number), "--json", "state", "--jq", ".state")
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to fetch pull request #%d state", number)
+	}
+
+	return strings.TrimSpace(stdOut.String()), nil
+}
+
+// deleteMergedBranch deletes the local and remote branch of a merged pull request, whose head commit is headOID.
+// The local branch is kept if it has commits that weren't merged. If the branch is checked out, the default branch
+// is checked out and pulled first.
+func deleteMergedBranch(remote string, branchName string, headOID string) error {
+	localOID, err := utils.Exec("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branchName)
+	hasLocalBranch := err == nil
+	if hasLocalBranch && strings.TrimSpace(localOID) != headOID {
+		log.Warnf("Local branch '%s' has commits that weren't merged, keeping it", branchName)
+		hasLocalBranch = false
+	}
+
+	current, err := fetchCurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	if hasLocalBranch && current == branchName {
+		defaultBranch, err := fetchDefaultBranch("")
+		if err != nil {
+			return err
+		}
+
+		if _, err := utils.Exec("git", "checkout", defaultBranch); err != nil {
+			return errors.Wrapf(err, "Failed to checkout '%s'", defaultBranch)
+		}
+
+		s := utils.StartSpinner(fmt.Sprintf("Pulling '%s'...", defaultBranch), fmt.Sprintf("Pulled '%s'", defaultBranch))
+		_, err = utils.Exec("git", "pull", remote, defaultBranch)
+		s.Stop()
+		if err != nil {
+			return errors.Wrapf(err, "Failed to pull '%s'", defaultBranch)
+		}
+	}
+
+	if hasLocalBranch {
+		if _, err := utils.Exec("git", "branch", "-D", branchName); err != nil {
+			return errors.Wrapf(err, "Failed to delete local branch '%s'", branchName)
+		}
+		log.Infof("Deleted local branch '%s'", branchName)
+	}
+
+	out, err := utils.Exec("git", "push", remote, "--delete", branchName)
+	if err != nil {
+		if strings.Contains(out, "remote ref does not exist") {
+			// The repository may delete head branches automatically
+			log.Debugf("Remote branch '%s' was already deleted", branchName)
+
+			return nil
+		}
+
+		return errors.Wrapf(err, "Failed to delete remote branch '%s'", branchName)
+	}
+	log.Infof("Deleted remote branch '%s'", branchName)
+
+	return nil
+}
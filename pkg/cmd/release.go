@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/cli/go-gh"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/release"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+type ReleaseOpts struct {
+	Confirm       bool
+	Bump          string
+	Version       string
+	GitHubRelease bool
+
+	DryRun bool
+}
+
+func NewReleaseCmd() *cobra.Command {
+	opts := &ReleaseOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Tag the next semantic version, based on the pull requests merged since the latest version.",
+		Long: heredoc.Docf(`
+			Tag the next semantic version, based on the pull requests merged since the latest version.
+
+			The latest version is the latest semantic version tag (e.g. %[1]sv1.2.3%[1]s) reachable from HEAD.
+			The pull requests merged since then are listed the same way %[1]sgh prx changelog%[1]s lists them,
+			and the next version is computed from their types:
+			- Major, if any of them is a breaking change
+			  (e.g. %[1]sfeat!: Drop node 16%[1]s, or labeled with one of %[1]srelease.major_labels%[1]s).
+			- Minor, if any of them is a feature (%[1]srelease.minor_types%[1]s in the config file).
+			- Patch, otherwise.
+
+			An annotated tag is created on HEAD with the changelog as its message and pushed.
+			With %[1]s--github-release%[1]s (or %[1]srelease.github_release: true%[1]s), a GitHub release is created as well,
+			with the changelog as its notes.
+		`, "`"),
+		Example: heredoc.Doc(`
+			$ gh prx release # Tag the next version
+			$ gh prx release --dry-run # Show the next version and the included pull requests
+			$ gh prx release --bump major # Force a major version bump
+			$ gh prx release --github-release # Also create a GitHub release
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return createRelease(opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.BoolVarP(&opts.Confirm, "confirm", "y", false, "Don't ask for user input")
+	fl.StringVar(&opts.Bump, "bump", "", "Override the computed version increment: {major|minor|patch}")
+	fl.StringVar(&opts.Version, "version", "", "Override the computed `version`")
+	cmd.MarkFlagsMutuallyExclusive("bump", "version")
+	fl.BoolVar(
+		&opts.GitHubRelease,
+		"github-release",
+		false,
+		"Create a GitHub release with the changelog as its notes (default: release.github_release config)",
+	)
+	fl.BoolVar(&opts.DryRun, "dry-run", false, "Print the next version and its changelog without tagging")
+
+	return cmd
+}
+
+func createRelease(opts *ReleaseOpts) error { // nolint:cyclop
+	if !opts.Confirm && !opts.DryRun && !utils.CanPrompt() {
+		return errors.Wrap(utils.ErrCannotPrompt, "Pass --confirm (-y) to tag the release without confirmation")
+	}
+
+	if opts.Bump != "" && !lo.Contains(release.Bumps, release.Bump(opts.Bump)) {
+		return errors.Errorf("Invalid bump '%s', should be one of major, minor or patch", opts.Bump)
+	}
+
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	latestTag, latest, err := fetchLatestVersionTag()
+	if err != nil {
+		return err
+	}
+
+	entries, err := fetchChangelogEntries(cfg, latestTag, "HEAD")
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 && opts.Version == "" && opts.Bump == "" {
+		log.Infof("Nothing to release since %s (pass --version or --bump to release anyway)", latestTag)
+
+		return nil
+	}
+
+	bump := release.NextBump(entries, cfg.Release, cfg.Changelog.ExcludeLabels)
+	if opts.Bump != "" {
+		bump = release.Bump(opts.Bump)
+	}
+	next := latest.Bump(bump).String()
+	if opts.Version != "" {
+		if _, err := release.ParseVersion(opts.Version); err != nil {
+			return err
+		}
+		next = opts.Version
+	}
+
+	fmt.Printf("\nPull requests merged since %s:\n\n", latestTag)
+	if len(entries) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, entry := range entries {
+		entryBump := string(release.EntryBump(entry, cfg.Release))
+		if lo.Some(entry.Labels, cfg.Changelog.ExcludeLabels) {
+			entryBump = "excluded from the changelog"
+		}
+		fmt.Printf("  #%d %s (%s)\n", entry.Number, entry.Title, entryBump)
+	}
+	fmt.Printf("\nNext version: %s (%s bump from %s)\n\n", next, bump, latestTag)
+
+	notes, err := renderChangelog(cfg, latestTag, "HEAD", next, entries)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Release notes:\n\n%s", notes)
+
+	if opts.DryRun {
+		fmt.Print(notes)
+		log.Info("Dry run enabled, skipping release")
+
+		return nil
+	}
+
+	if !opts.Confirm {
+		proceed := false
+		if err := survey.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Tag %s?", next),
+			Default: true,
+		}, &proceed); err != nil {
+			return errors.Wrap(err, "Failed to confirm release")
+		}
+		if !proceed {
+			log.Info("Aborted")
+
+			return nil
+		}
+	}
+
+	// Verbatim cleanup keeps the markdown headings, which would otherwise be stripped as comments
+	if _, err := utils.Exec("git", "tag", "--annotate", "--cleanup=verbatim", "--message", notes, next); err != nil {
+		return errors.Wrapf(err, "Failed to create tag '%s'", next)
+	}
+	log.Infof("Created tag '%s'", next)
+
+	s := utils.StartSpinner(fmt.Sprintf("Pushing tag '%s'...", next), fmt.Sprintf("Pushed tag '%s'", next))
+	_, err = utils.Exec("git", "push", cfg.PR.PushRemote, "refs/tags/"+next)
+	s.Stop()
+	if err != nil {
+		// Delete the local tag, so the release can be retried
+		if _, deleteErr := utils.Exec("git", "tag", "--delete", next); deleteErr != nil {
+			log.WithError(deleteErr).Warnf("Failed to delete local tag '%s'", next)
+		}
+
+		return errors.Wrapf(err, "Failed to push tag '%s'", next)
+	}
+
+	if !opts.GitHubRelease && !*cfg.Release.GitHubRelease {
+		return nil
+	}
+
+	s = utils.StartSpinner("Creating GitHub release...", "Created GitHub release")
+	stdOut, _, err := gh.Exec("release", "create", next, "--verify-tag", "--title", next, "--notes", notes)
+	s.Stop()
+	if err != nil {
+		return errors.Wrapf(err, "Failed to create GitHub release '%s'", next)
+	}
+	log.Info(strings.TrimSpace(stdOut.String()))
+
+	return nil
+}
+
+// fetchLatestVersionTag returns the latest semantic version tag reachable from HEAD, and its version.
+func fetchLatestVersionTag() (string, release.Version, error) {
+	out, err := utils.Exec("git", "tag", "--merged", "HEAD", "--sort=-version:refname")
+	if err != nil {
+		return "", release.Version{}, errors.Wrap(err, "Failed to list tags")
+	}
+
+	for _, tag := range lo.Compact(strings.Split(out, "\n")) {
+		if version, err := release.ParseVersion(tag); err == nil {
+			return tag, version, nil
+		}
+	}
+
+	return "", release.Version{}, errors.New(
+		"No semantic version tag found, create the first version with 'git tag -a v0.1.0 -m v0.1.0'",
+	)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/repository"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+const upstreamRemote = "upstream"
+
+// resolveRepoRemotes detects whether the push remote is a fork. The base repository is the "upstream" remote's
+// repository if one exists, or the fork's parent on GitHub.
+func resolveRepoRemotes(pushRemote string) (*models.RepoRemotes, error) {
+	remotes := &models.RepoRemotes{PushRemote: pushRemote}
+
+	pushRepo, err := fetchRemoteRepo(pushRemote)
+	if err != nil {
+		return nil, err
+	}
+
+	baseRepo, baseRemote, err := resolveBaseRepo(pushRemote, pushRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	if baseRepo != "" {
+		remotes.HeadOwner = pushRepo.Owner()
+		remotes.BaseRepo = baseRepo
+		remotes.BaseRemote = baseRemote
+		log.Debugf("Remote '%s' is a fork of '%s'", pushRemote, baseRepo)
+	}
+
+	return remotes, nil
+}
+
+// resolveBaseRepo returns the repository that the push remote's repository is a fork of and its remote, or empty
+// strings if it isn't a fork. The "upstream" remote is checked first, then the fork's parent on GitHub, which is
+// cached in the git config (gh-prx.<repository>.parent) to avoid fetching it on every run.
+func resolveBaseRepo(pushRemote string, pushRepo repository.Repository) (string, string, error) {
+	out, err := utils.Exec("git", "remote")
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to list git remotes")
+	}
+	gitRemotes := strings.Split(strings.TrimSpace(out), "\n")
+
+	if pushRemote != upstreamRemote && lo.Contains(gitRemotes, upstreamRemote) {
+		upstreamRepo, err := fetchRemoteRepo(upstreamRemote)
+		if err != nil {
+			return "", "", err
+		}
+
+		if repoFullName(upstreamRepo) != repoFullName(pushRepo) {
+			return repoFullName(upstreamRepo), upstreamRemote, nil
+		}
+	}
+
+	cacheKey := fmt.Sprintf("gh-prx.%s.parent", repoFullName(pushRepo))
+	if out, err := utils.Exec("git", "config", "--get", cacheKey); err == nil {
+		baseRepo := strings.TrimSpace(out)
+
+		return baseRepo, findRepoRemote(gitRemotes, baseRepo), nil
+	}
+
+	s := utils.StartSpinner("Resolving repository remotes...", "Resolved repository remotes")
+	stdOut, _, err := gh.Exec(
+		"repo", "view", repoFullName(pushRepo),
+		"--json", "parent", "--jq", `.parent | select(. != null) | .owner.login + "/" + .name`,
+	)
+	s.Stop()
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Failed to fetch repository of remote '%s'", pushRemote)
+	}
+
+	baseRepo := strings.TrimSpace(stdOut.String())
+	if baseRepo != "" {
+		baseRepo = pushRepo.Host() + "/" + baseRepo
+	}
+
+	if _, err := utils.Exec("git", "config", cacheKey, baseRepo); err != nil {
+		log.WithError(err).Debug("Failed to cache the parent repository")
+	}
+
+	return baseRepo, findRepoRemote(gitRemotes, baseRepo), nil
+}
+
+// findRepoRemote returns the git remote of a repository, or an empty string if it has none.
+func findRepoRemote(gitRemotes []string, repo string) string {
+	if repo == "" {
+		return ""
+	}
+
+	remote, _ := lo.Find(gitRemotes, func(remote string) bool {
+		remoteRepo, err := fetchRemoteRepo(remote)
+
+		return err == nil && repoFullName(remoteRepo) == repo
+	})
+
+	return remote
+}
+
+func fetchRemoteRepo(remote string) (repository.Repository, error) {
+	out, err := utils.Exec("git", "remote", "get-url", remote)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get the url of remote '%s'", remote)
+	}
+
+	repo, err := repository.Parse(strings.TrimSpace(out))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse the url of remote '%s'", remote)
+	}
+
+	return repo, nil
+}
+
+func repoFullName(repo repository.Repository) string {
+	return fmt.Sprintf("%s/%s/%s", repo.Host(), repo.Owner(), repo.Name())
+}
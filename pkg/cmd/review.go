@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/cli/go-gh"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/ilaif/gh-prx/pkg/ai"
+	"github.com/ilaif/gh-prx/pkg/branch"
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+type ReviewOpts struct {
+	BaseBranch string
+	Post       bool
+	AITimeout  time.Duration
+}
+
+func NewReviewCmd() *cobra.Command {
+	opts := &ReviewOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Review the current branch changes using AI.",
+		Long: heredoc.Docf(`
+			Review the current branch changes using AI.
+
+			The diff of the current branch against the base branch is sent to the configured AI model
+			and the findings are printed as %[1]sfile:line%[1]s anchored comments.
+
+			When a pull request already exists for the current branch, the findings can be posted to it
+			as a pending review using %[1]s--post%[1]s. Pending reviews are only visible to you until submitted.
+			The branch should be pushed first, so the pull request's head commit is the local HEAD.
+
+			The review prompt can be customized using %[1]sai.review%[1]s in the config file.
+		`, "`"),
+		Example: heredoc.Doc(`
+			$ gh prx review # Review the current branch against the default branch
+			$ gh prx review --base develop # Review the current branch against develop
+			$ gh prx review --post # Post the findings as a pending review on the branch's pull request
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return review(cmd.Context(), opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.StringVarP(&opts.BaseBranch, "base", "B", "", "The `branch` to compare the changes against")
+	fl.BoolVar(&opts.Post, "post", false, "Post the findings as a pending review on the branch's pull request")
+	fl.DurationVar(&opts.AITimeout, "ai-timeout", 0, "The maximum time to wait for the AI review (default: ai.timeout)")
+
+	return cmd
+}
+
+func review(ctx context.Context, opts *ReviewOpts) error {
+	if !ai.IsAISummarizerAvailable() {
+		return errors.New("AI is not configured, please export an OpenAI key as OPENAI_API_KEY")
+	}
+
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	if opts.AITimeout > 0 {
+		cfg.AI.Timeout = opts.AITimeout
+	}
+
+	branchName, err := fetchCurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	// The pull request is checked before reviewing, so the review isn't wasted if it can't be posted
+	var prInfo *reviewedPR
+	if opts.Post {
+		if prInfo, err = fetchReviewedPR(); err != nil {
+			return err
+		}
+	}
+
+	baseBranch := opts.BaseBranch
+	if baseBranch == "" {
+		baseBranch, err = fetchDefaultBranch("")
+		if err != nil {
+			return err
+		}
+	}
+
+	gitDiffOutput, err := fetchReviewDiff(baseBranch)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(gitDiffOutput) == "" {
+		log.Info("No changes to review")
+
+		return nil
+	}
+
+	commits, err := fetchBranchCommits(branchName, baseBranch)
+	if err != nil {
+		return err
+	}
+
+	promptData := map[string]any{}
+	if b, err := branch.ParseBranch(branchName, cfg.Branch); err == nil {
+		promptData = lo.Assign(b.Fields, promptData)
+	} else {
+		log.Debugf("Branch name does not match the configured pattern, skipping branch fields: %s", err)
+	}
+	promptData["Diff"] = gitDiffOutput
+	promptData["Commits"] = models.CommitSubjects(commits)
+	promptData["CommitMessages"] = models.CommitMessages(commits)
+
+	funcMaps, err := utils.GenerateTemplateFunctions(cfg.Branch.TokenSeparators)
+	if err != nil {
+		return errors.Wrap(err, "Failed to generate template functions")
+	}
+
+	prompt, err := ai.RenderPrompts(cfg.AI.Review, funcMaps, promptData)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.AI.Timeout)
+	defer cancel()
+
+	s := utils.StartSpinner("Reviewing changes using AI...", "Finished reviewing")
+	findings, err := ai.ReviewGitDiffOutput(ctx, cfg.AI.Model, prompt)
+	s.Stop()
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+
+		return findings[i].Line < findings[j].Line
+	})
+
+	if len(findings) == 0 {
+		log.Info("No findings, looks good!")
+
+		return nil
+	}
+
+	for _, f := range findings {
+		fmt.Printf("%s:%d: [%s] %s\n", f.File, f.Line, f.Severity, f.Message)
+	}
+
+	if !opts.Post {
+		return nil
+	}
+
+	return postPendingReview(prInfo, findings, ai.ParseDiffLineRanges(gitDiffOutput))
+}
+
+type reviewedPR struct {
+	Number     int    `json:"number"`
+	HeadRefOid string `json:"headRefOid"`
+}
+
+type ghReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+type ghReview struct {
+	CommitID string            `json:"commit_id"`
+	Body     string            `json:"body"`
+	Comments []ghReviewComment `json:"comments"`
+}
+
+// fetchReviewedPR returns the pull request of the current branch. Its head commit should be the local HEAD, since
+// the review comments are anchored to the lines of the local diff.
+func fetchReviewedPR() (*reviewedPR, error) {
+	stdOut, _, err := gh.Exec("pr", "view", "--json", "number,headRefOid")
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to find a pull request for the current branch, please create it first")
+	}
+
+	prInfo := &reviewedPR{}
+	if err := json.Unmarshal(stdOut.Bytes(), prInfo); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse pull request")
+	}
+
+	out, err := utils.Exec("git", "rev-parse", "HEAD")
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to resolve HEAD")
+	}
+	if head := strings.TrimSpace(out); head != prInfo.HeadRefOid {
+		return nil, errors.Errorf(
+			"The head of pull request #%d (%s) isn't the local HEAD (%s), push the branch (or pull it) before posting",
+			prInfo.Number, prInfo.HeadRefOid, head,
+		)
+	}
+
+	return prInfo, nil
+}
+
+// postPendingReview posts the findings as a pending review on the pull request of the current branch.
+// Findings that can't be anchored to a changed line are added to the review body, as GitHub rejects
+// review comments outside the diff.
+func postPendingReview(prInfo *reviewedPR, findings []ai.ReviewFinding, lineRanges ai.DiffLineRanges) error {
+	r := ghReview{CommitID: prInfo.HeadRefOid, Comments: []ghReviewComment{}}
+	unanchored := []string{}
+	for _, f := range findings {
+		body := fmt.Sprintf("**%s**: %s", f.Severity, f.Message)
+		if !lineRanges.Contains(f.File, f.Line) {
+			unanchored = append(unanchored, fmt.Sprintf("- `%s:%d` %s", f.File, f.Line, body))
+
+			continue
+		}
+
+		r.Comments = append(r.Comments, ghReviewComment{Path: f.File, Line: f.Line, Side: "RIGHT", Body: body})
+	}
+
+	r.Body = "🔮 AI-powered review by gh-prx"
+	if len(unanchored) > 0 {
+		r.Body += "\n\n" + strings.Join(unanchored, "\n")
+	}
+
+	reviewBytes, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal review")
+	}
+
+	tmpFile, err := os.CreateTemp(os.TempDir(), "gh-prx-review-*.json")
+	if err != nil {
+		return errors.Wrap(err, "Failed to create temp file")
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(reviewBytes); err != nil {
+		return errors.Wrap(err, "Failed to write review to temp file")
+	}
+
+	s := utils.StartSpinner("Posting pending review...", "Posted pending review")
+	_, _, err = gh.Exec(
+		"api", fmt.Sprintf("repos/{owner}/{repo}/pulls/%d/reviews", prInfo.Number),
+		"--method", "POST",
+		"--input", tmpFile.Name(),
+	)
+	s.Stop()
+	if err != nil {
+		return errors.Wrap(err, "Failed to post pending review")
+	}
+
+	log.Infof("Posted %d comment(s) as a pending review on pull request #%d", len(r.Comments), prInfo.Number)
+
+	return nil
+}
+
+// fetchReviewDiff returns the diff of the current branch changes since it forked from the base branch,
+// with context lines, as GitHub shows it in pull requests.
+func fetchReviewDiff(baseBranch string) (string, error) {
+	out, err := utils.Exec("git", "diff", "--unified=3", baseBranch+"...HEAD")
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to fetch branch diff")
+	}
+
+	return out, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/ilaif/gh-prx/pkg/cmd/setup"
+	"github.com/ilaif/gh-prx/pkg/utils"
 )
 
 func Execute(version string) {
@@ -22,6 +23,7 @@ func Execute(version string) {
 
 func NewRootCmd(version string) *cobra.Command {
 	var debug bool
+	var noPrompt bool
 
 	rootCmd := &cobra.Command{
 		Use:           "prx",
@@ -37,15 +39,37 @@ func NewRootCmd(version string) *cobra.Command {
 				log.Info("Debug logs enabled")
 				log.SetLevel(log.DebugLevel)
 			}
+
+			if noPrompt {
+				utils.DisablePrompts()
+			}
+			if !utils.CanPrompt() {
+				log.Debug("Interactive prompts disabled, running as with --confirm")
+			}
 		},
 	}
 
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "verbose logging")
+	rootCmd.PersistentFlags().BoolVar(
+		&noPrompt,
+		"no-prompt",
+		false,
+		"disable interactive prompts, failing when input is missing (default: when not in a terminal, or CI=true)",
+	)
 
 	rootCmd.AddCommand(
 		setup.NewSetupCmd(),
 		NewCreateCmd(),
+		NewUpdateCmd(),
+		NewMergeCmd(),
+		NewChangelogCmd(),
+		NewReleaseCmd(),
+		NewBackportCmd(),
+		NewLintCmd(),
+		NewHooksCmd(),
 		NewCheckoutNewCmd(),
+		NewReviewCmd(),
+		NewStackCmd(),
 	)
 
 	return rootCmd
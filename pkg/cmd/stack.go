@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/cli/go-gh"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/stack"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+func NewStackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stack",
+		Short: "Stacked pull requests commands.",
+		Long: heredoc.Docf(`
+			Stacked pull requests commands.
+
+			A stack is a chain of dependent branches, where each branch is based on its parent branch.
+			Parent relationships are recorded in the git config (%[1]sbranch.<name>.gh-prx-parent%[1]s).
+
+			%[1]sgh prx create%[1]s uses the parent branch as the pull request base,
+			and adds a stack navigation table to each pull request of the stack.
+		`, "`"),
+		Example: heredoc.Doc(`
+			// Create a branch stacked on the current branch and checkout to it:
+			$ gh prx stack create feat/add-bar
+
+			// Show the stack of the current branch:
+			$ gh prx stack list
+
+			// Rebase the stack after its bottom pull request was merged:
+			$ gh prx stack sync
+		`),
+	}
+
+	cmd.AddCommand(
+		NewStackCreateCmd(),
+		NewStackListCmd(),
+		NewStackSyncCmd(),
+	)
+
+	return cmd
+}
+
+func NewStackCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "create <branch>",
+		Short:   "Create a new branch stacked on the current branch and checkout to it.",
+		Aliases: []string{"new"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return stackCreate(args[0])
+		},
+	}
+}
+
+func NewStackListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "Show the stack of the current branch.",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return stackList()
+		},
+	}
+}
+
+func NewStackSyncCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "Rebase the stack of the current branch and update its pull requests.",
+		Long: heredoc.Docf(`
+			Rebase the stack of the current branch and update its pull requests.
+
+			Every branch of the stack is rebased on its parent, starting with the bottom branch which is rebased
+			on the latest trunk of the base repository (e.g. %[1]smain%[1]s of %[1]spr.push_remote%[1]s, or of the
+			%[1]supstream%[1]s remote of a fork). Branches whose pull request was merged are removed from the stack,
+			and their children are rebased on the merged branch's parent.
+
+			Afterwards, the branches are force-pushed (with lease) to %[1]spr.push_remote%[1]s, and the pull requests' bases and
+			stack navigation tables are updated.
+
+			When a rebase stops due to conflicts, resolve them, run %[1]sgit rebase --continue%[1]s
+			and run %[1]sgh prx stack sync%[1]s again.
+		`, "`"),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return stackSync(cmd.Context())
+		},
+	}
+}
+
+func stackCreate(name string) error {
+	current, err := fetchCurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	out, err := utils.Exec("git", "checkout", "-b", name)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create branch")
+	}
+	log.Info(strings.Trim(out, "\n"))
+
+	if err := stack.SetParent(name, current); err != nil {
+		return err
+	}
+
+	log.Infof("Branch '%s' is stacked on '%s'", name, current)
+
+	return nil
+}
+
+func stackList() error {
+	current, err := fetchCurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	parents, err := stack.Parents()
+	if err != nil {
+		return err
+	}
+
+	trunk, branches := stack.Order(parents, current)
+	if trunk == "" {
+		return errors.Errorf("Branch '%s' is not part of a stack, use 'gh prx stack create' to create one", current)
+	}
+
+	remotes, err := resolveRepoRemotes(cfg.PR.PushRemote)
+	if err != nil {
+		return err
+	}
+
+	depths := map[string]int{trunk: 0}
+	fmt.Println(trunk)
+	for _, b := range branches {
+		depths[b] = depths[parents[b]] + 1
+
+		existingPR, err := fetchExistingPR(remotes, b)
+		if err != nil {
+			return err
+		}
+
+		info := ""
+		if existingPR != nil {
+			info = fmt.Sprintf(" #%d (%s)", existingPR.Number, strings.ToLower(existingPR.State))
+		}
+		if b == current {
+			info += " 👈"
+		}
+
+		fmt.Printf("%s└─ %s%s\n", strings.Repeat("   ", depths[b]-1), b, info)
+	}
+
+	return nil
+}
+
+func stackSync(_ context.Context) error { // nolint:cyclop
+	current, err := fetchCurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	parents, err := stack.Parents()
+	if err != nil {
+		return err
+	}
+
+	trunk, branches := stack.Order(parents, current)
+	if trunk == "" {
+		return errors.Errorf("Branch '%s' is not part of a stack, use 'gh prx stack create' to create one", current)
+	}
+
+	remotes, err := resolveRepoRemotes(cfg.PR.PushRemote)
+	if err != nil {
+		return err
+	}
+
+	// The trunk is fetched from the base repository, e.g. the "upstream" remote of a fork
+	trunkSHA, err := fetchRef(remotes.FetchSource(), trunk)
+	if err != nil {
+		return err
+	}
+
+	prs := map[string]*ExistingPR{}
+	oldSHAs := map[string]string{}
+	for _, b := range branches {
+		if prs[b], err = fetchExistingPR(remotes, b); err != nil {
+			return err
+		}
+
+		out, err := utils.Exec("git", "rev-parse", b)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to resolve branch '%s'", b)
+		}
+		oldSHAs[b] = strings.TrimSpace(out)
+	}
+
+	isMerged := func(b string) bool {
+		return prs[b] != nil && prs[b].State == "MERGED"
+	}
+
+	merged := []string{}
+	for _, b := range branches {
+		if isMerged(b) {
+			merged = append(merged, b)
+
+			continue
+		}
+
+		oldParent := parents[b]
+		parent := oldParent
+		for parent != trunk && isMerged(parent) {
+			parent = parents[parent]
+		}
+
+		if parent != oldParent {
+			log.Infof("'%s' was merged, restacking '%s' on '%s'", oldParent, b, parent)
+			if err := stack.SetParent(b, parent); err != nil {
+				return err
+			}
+		}
+
+		if err := rebaseStackBranch(b, parent, trunk, trunkSHA, oldSHAs[oldParent]); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range merged {
+		if err := stack.UnsetParent(b); err != nil {
+			return err
+		}
+		log.Infof("Removed merged branch '%s' from the stack, delete it using 'git branch -D %s'", b, b)
+	}
+
+	for _, b := range branches {
+		if prs[b] == nil || prs[b].State != "OPEN" {
+			continue
+		}
+
+		if err := pushStackBranch(remotes.PushRemote, b, prs[b]); err != nil {
+			return err
+		}
+	}
+
+	checkoutBranch := current
+	if isMerged(current) {
+		checkoutBranch = trunk
+	}
+	if _, err := utils.Exec("git", "checkout", checkoutBranch); err != nil {
+		return errors.Wrapf(err, "Failed to checkout '%s'", checkoutBranch)
+	}
+
+	return refreshStackNavigation(remotes, checkoutBranch)
+}
+
+// rebaseStackBranch rebases a branch of a stack on its parent, or on the fetched trunk (trunkSHA). Commits of the
+// parent's previous version (identified by oldParentSHA) are excluded, so they don't get re-applied if the parent
+// was rebased or merged.
+func rebaseStackBranch(b string, parent string, trunk string, trunkSHA string, oldParentSHA string) error {
+	args := []string{"rebase"}
+	if parent == trunk {
+		args = append(args, trunkSHA)
+		if oldParentSHA != "" {
+			args = []string{"rebase", "--onto", trunkSHA, oldParentSHA}
+		}
+	} else {
+		args = append(args, "--onto", parent, oldParentSHA)
+	}
+	args = append(args, b)
+
+	s := utils.StartSpinner(fmt.Sprintf("Rebasing '%s' on '%s'...", b, parent), fmt.Sprintf("Rebased '%s'", b))
+	_, err := utils.Exec("git", args...)
+	s.Stop()
+	if err != nil {
+		return errors.Wrapf(err, heredoc.Doc(`
+			Failed to rebase '%[1]s' on '%[2]s'.
+			If there are conflicts, resolve them, run 'git rebase --continue' and run 'gh prx stack sync' again.
+			To cancel, run 'git rebase --abort'`), b, parent)
+	}
+
+	return nil
+}
+
+func pushStackBranch(remote string, b string, existingPR *ExistingPR) error {
+	s := utils.StartSpinner(fmt.Sprintf("Pushing '%s'...", b), fmt.Sprintf("Pushed '%s'", b))
+	_, err := utils.Exec("git", "push", "--force-with-lease", remote, b)
+	s.Stop()
+	if err != nil {
+		return errors.Wrapf(err, "Failed to push '%s'", b)
+	}
+
+	parent, err := stack.GetParent(b)
+	if err != nil {
+		return err
+	}
+
+	if parent != "" && parent != existingPR.BaseRefName {
+		if _, _, err := gh.Exec("pr", "edit", existingPR.URL, "--base", parent); err != nil {
+			return errors.Wrapf(err, "Failed to change the base of pull request #%d to '%s'", existingPR.Number, parent)
+		}
+		log.Infof("Changed the base of pull request #%d to '%s'", existingPR.Number, parent)
+	}
+
+	return nil
+}
+
+// refreshStackNavigation adds or updates the stack navigation table of every open pull request in the stack
+// of a branch.
+func refreshStackNavigation(remotes *models.RepoRemotes, branch string) error {
+	parents, err := stack.Parents()
+	if err != nil {
+		return err
+	}
+
+	trunk, branches := stack.Order(parents, branch)
+	if trunk == "" {
+		return nil
+	}
+
+	s := utils.StartSpinner("Updating stack navigation...", "Updated stack navigation")
+	defer s.Stop()
+
+	entries := []stack.Entry{}
+	prs := map[string]*ExistingPR{}
+	for _, b := range branches {
+		existingPR, err := fetchExistingPR(remotes, b)
+		if err != nil {
+			return err
+		}
+
+		entry := stack.Entry{Branch: b}
+		if existingPR != nil {
+			entry.PRNumber = existingPR.Number
+			entry.PRState = existingPR.State
+			prs[b] = existingPR
+		}
+		entries = append(entries, entry)
+	}
+
+	for b, existingPR := range prs {
+		if existingPR.State != "OPEN" {
+			continue
+		}
+
+		body := stack.ReplaceNavigation(existingPR.Body, stack.RenderNavigation(trunk, entries, b))
+		if body == existingPR.Body {
+			continue
+		}
+
+		if _, _, err := gh.Exec("pr", "edit", existingPR.URL, "--body", body); err != nil {
+			return errors.Wrapf(err, "Failed to update the stack navigation of pull request #%d", existingPR.Number)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/caarlos0/log"
+	"github.com/pkg/errors"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+// loadPRTemplate overrides the configured PR body with the repository's pull request template, if one exists.
+func loadPRTemplate(cfg *config.RepositoryConfig, b models.Branch, confirm bool) error {
+	templatePath, err := selectPRTemplate(cfg, b, confirm)
+	if err != nil {
+		return err
+	}
+
+	if templatePath == "" {
+		log.Debug("No pull request template found, using the default template")
+
+		return nil
+	}
+
+	prTemplateBytes, err := utils.ReadFile(templatePath)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read pull request template")
+	}
+	cfg.PR.Body = string(prTemplateBytes)
+
+	return nil
+}
+
+// selectPRTemplate returns the path of the pull request template to use, or an empty string to use the default
+// (see pr.SelectTemplate).
+func selectPRTemplate(cfg *config.RepositoryConfig, b models.Branch, confirm bool) (string, error) {
+	templatesDir, err := findOptionalPathInRepo(cfg.PullRequestTemplatesDir)
+	if err != nil {
+		return "", err
+	}
+	if templatesDir == "" && cfg.PullRequestTemplatesDir != config.DefaultPullRequestTemplatesDir {
+		return "", errors.Errorf("Failed to find pull request templates directory '%s'", cfg.PullRequestTemplatesDir)
+	}
+
+	files, err := pr.ListTemplates(templatesDir)
+	if err != nil {
+		return "", err
+	}
+
+	singleTemplatePath, err := findOptionalPathInRepo(cfg.PullRequestTemplatePath)
+	if err != nil {
+		return "", err
+	}
+	if singleTemplatePath == "" && cfg.PullRequestTemplatePath != config.DefaultPullRequestTemplatePath {
+		return "", errors.Errorf("Failed to find pull request template '%s'", cfg.PullRequestTemplatePath)
+	}
+
+	branchType, _ := b.Fields["Type"].(string)
+
+	return pr.SelectTemplate(pr.Templates{
+		Dir:        templatesDir,
+		Files:      files,
+		ByType:     cfg.PullRequestTemplates,
+		SinglePath: singleTemplatePath,
+	}, branchType, confirm, func(options []string) (string, error) {
+		selected := ""
+		if err := survey.AskOne(&survey.Select{
+			Message: "Select a pull request template:",
+			Options: options,
+		}, &selected, survey.WithValidator(survey.Required)); err != nil {
+			return "", errors.Wrap(err, "Failed to ask for pull request template")
+		}
+
+		return selected, nil
+	})
+}
+
+// findOptionalPathInRepo returns the path of a file in the repository, or an empty string if it doesn't exist.
+func findOptionalPathInRepo(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	fullPath, err := utils.FindRelativePathInRepo(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", errors.Wrapf(err, "Failed to find '%s'", path)
+	}
+
+	return fullPath, nil
+}
+
+// setTemplateVariables sets template variables (e.g. branch fields) from "Key=Value" values of the --set flag.
+func setTemplateVariables(fields map[string]any, values []string) error {
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return errors.Errorf("Invalid --set value '%s': should be Key=Value", value)
+		}
+		fields[key] = val
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/cli/go-gh"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ilaif/gh-prx/pkg/branch"
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+type UpdateOpts struct {
+	Confirm   bool
+	KeepTitle bool
+	Force     bool
+
+	AIOpts
+
+	Set []string
+
+	DryRun bool
+}
+
+// ExistingPR is a pull request that already exists on GitHub.
+type ExistingPR struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	BaseRefName string `json:"baseRefName"`
+	HeadRefName string `json:"headRefName"`
+	State       string `json:"state"`
+	URL         string `json:"url"`
+}
+
+func NewUpdateCmd() *cobra.Command {
+	opts := &UpdateOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the pull request of the current branch, extended.",
+		Long: heredoc.Docf(`
+			Update the pull request of the current branch, extended.
+
+			The pull request title and description (body) are re-rendered based on the latest commits
+			(and AI-powered summary, if enabled), the same way %[1]sgh prx create%[1]s renders them.
+
+			Only the generated section of the description, delimited by %[1]s%[2]s%[1]s and %[1]s%[3]s%[1]s,
+			is replaced. Text that was added outside of it is preserved, and checklist items that were
+			already answered keep their answers.
+		`, "`", pr.GeneratedSectionStart, pr.GeneratedSectionEnd),
+		Example: heredoc.Doc(`
+			$ gh prx update # Re-render the pull request title and description
+			$ gh prx update --keep-title # Only re-render the pull request description
+			$ gh prx update --dry-run # Print the updated pull request without updating it
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return update(cmd.Context(), opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.BoolVarP(&opts.Confirm, "confirm", "y", false, "Don't ask for user input")
+	fl.BoolVar(&opts.KeepTitle, "keep-title", false, "Don't update the pull request title")
+	fl.BoolVar(
+		&opts.Force,
+		"force",
+		false,
+		"Replace the whole description when it has no generated section (e.g. created before gh prx update existed)",
+	)
+	addAIFlags(fl, &opts.AIOpts)
+	fl.StringArrayVar(&opts.Set, "set", nil, "Set a template variable, overriding the branch fields: `Key=Value`")
+	fl.BoolVar(&opts.DryRun, "dry-run", false, "Print the pull request body and title without updating the pull request")
+
+	return cmd
+}
+
+func update(ctx context.Context, opts *UpdateOpts) error {
+	opts.Confirm = opts.Confirm || !utils.CanPrompt()
+
+	setupCfg, err := config.LoadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadRepositoryConfig(setupCfg.RepositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	if opts.AITimeout > 0 {
+		cfg.AI.Timeout = opts.AITimeout
+	}
+
+	branchName, err := fetchCurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	remotes, err := resolveRepoRemotes(cfg.PR.PushRemote)
+	if err != nil {
+		return err
+	}
+
+	existingPR, err := fetchExistingPR(remotes, branchName)
+	if err != nil {
+		return err
+	}
+	if existingPR == nil {
+		return errors.Errorf("No pull request found for branch '%s', please create one with 'gh prx create'", branchName)
+	}
+	if existingPR.State != "OPEN" {
+		return errors.Errorf("Pull request #%d is %s", existingPR.Number, strings.ToLower(existingPR.State))
+	}
+
+	return updatePR(ctx, setupCfg, cfg, remotes, existingPR, opts)
+}
+
+func updatePR(
+	ctx context.Context,
+	setupCfg *config.SetupConfig,
+	cfg *config.RepositoryConfig,
+	remotes *models.RepoRemotes,
+	existingPR *ExistingPR,
+	opts *UpdateOpts,
+) error {
+	b, err := branch.ParseBranch(existingPR.HeadRefName, cfg.Branch)
+	if err != nil {
+		return err
+	}
+	if err := setTemplateVariables(b.Fields, opts.Set); err != nil {
+		return err
+	}
+
+	if *cfg.PR.PushToRemote {
+		if err := pushBranch(remotes.PushRemote, b.Original); err != nil {
+			return err
+		}
+	}
+
+	if err := loadPRTemplate(cfg, b, opts.Confirm); err != nil {
+		return err
+	}
+
+	commits, err := fetchBranchCommits(b.Original, existingPR.BaseRefName)
+	if err != nil {
+		return err
+	}
+
+	previousAnswers := pr.ParseChecklistAnswers(existingPR.Body)
+	aiSummarizer := newAISummarizer(ctx, setupCfg, cfg, b, existingPR.BaseRefName, commits, opts.AIOpts, opts.Confirm)
+	aiChecklistAnswerer := newAIChecklistAnswerer(ctx, cfg, b, existingPR.BaseRefName, commits, opts.AIOpts)
+	checklistAnswerer := func(questions []string) (map[string]pr.ChecklistAnswer, error) {
+		answers := map[string]pr.ChecklistAnswer{}
+		unanswered := []string{}
+		for _, q := range questions {
+			if answer, ok := previousAnswers[q]; ok {
+				answers[q] = answer
+			} else {
+				unanswered = append(unanswered, q)
+			}
+		}
+
+		if aiChecklistAnswerer != nil && len(unanswered) > 0 {
+			aiAnswers, err := aiChecklistAnswerer(unanswered)
+			if err != nil {
+				return nil, err
+			}
+			for q, answer := range aiAnswers {
+				answers[q] = answer
+			}
+		}
+
+		return answers, nil
+	}
+
+	newPR, err := pr.TemplatePR(
+		b, cfg.PR, opts.Confirm, cfg.Branch.TokenSeparators, pr.NewIssueLinker(cfg, setupCfg),
+		commits, aiSummarizer, checklistAnswerer,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !*cfg.PR.AnswerChecklist {
+		newPR.Body = pr.ApplyChecklistAnswers(newPR.Body, previousAnswers)
+	}
+
+	body, err := pr.ReplaceGeneratedSection(existingPR.Body, newPR.Body)
+	if err != nil {
+		if !errors.Is(err, pr.ErrNoGeneratedSection) || !opts.Force {
+			return errors.Wrapf(err,
+				"Failed to update pull request #%d description, use --force to replace the whole description",
+				existingPR.Number,
+			)
+		}
+
+		log.Warn("No generated section found in the existing description, replacing the whole description")
+		body = newPR.Body
+	}
+
+	title := newPR.Title
+	if opts.KeepTitle {
+		title = existingPR.Title
+	}
+
+	log.Debug(fmt.Sprintf("Pull request title: %s", title))
+	log.Debug(fmt.Sprintf("Pull request body:\n\n%s", body))
+
+	if opts.DryRun {
+		log.Info("Dry run enabled, skipping pull request update")
+
+		return nil
+	}
+
+	if len(newPR.Labels) > 0 {
+		if newPR.Labels, err = prepareLabels(cfg.PR, newPR.Labels); err != nil {
+			return err
+		}
+	}
+
+	s := utils.StartSpinner("Updating pull request...", "Updated pull request")
+	// The URL identifies the pull request in its repository, e.g. when it's opened from a fork
+	args := []string{"pr", "edit", existingPR.URL, "--title", title, "--body", body}
+	if len(newPR.Labels) > 0 {
+		args = append(args, "--add-label", strings.Join(newPR.Labels, ","))
+	}
+	_, _, err = gh.Exec(args...)
+	s.Stop()
+	if err != nil {
+		return errors.Wrap(err, "Failed to update pull request")
+	}
+	log.Info(existingPR.URL)
+
+	return nil
+}
+
+// fetchExistingPR returns the pull request of a branch in the base repository (e.g. the fork's parent), or nil if
+// there is none.
+func fetchExistingPR(remotes *models.RepoRemotes, branchName string) (*ExistingPR, error) {
+	return fetchRepoPR(remotes.BaseRepo, remotes.HeadRef(branchName))
+}
+
+// fetchRepoPR returns the pull request of a branch (e.g. "OWNER:BRANCH" for a fork) in a repository (the current
+// repository if empty), or nil if there is none.
+func fetchRepoPR(repo string, branchName string) (*ExistingPR, error) {
+	args := []string{"pr", "view", branchName, "--json", "number,title,body,baseRefName,headRefName,state,url"}
+	if repo != "" {
+		args = append(args, "--repo", repo)
+	}
+
+	stdOut, stdErr, err := gh.Exec(args...)
+	if err != nil {
+		if strings.Contains(stdErr.String(), "no pull requests found") {
+			return nil, nil // nolint:nilnil
+		}
+
+		return nil, errors.Wrapf(err, "Failed to fetch pull request for branch '%s'", branchName)
+	}
+
+	existingPR := &ExistingPR{}
+	if err := json.Unmarshal(stdOut.Bytes(), existingPR); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse pull request")
+	}
+	// Descriptions edited on GitHub use CRLF line endings.
+	existingPR.Body = strings.ReplaceAll(existingPR.Body, "\r\n", "\n")
+
+	return existingPR, nil
+}
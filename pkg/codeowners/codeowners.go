@@ -0,0 +1,104 @@
+package codeowners
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+// Paths are the locations of the CODEOWNERS file, by precedence.
+// See https://docs.github.com/articles/about-code-owners#codeowners-file-location
+var Paths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is a CODEOWNERS line, assigning owners to the files matching a pattern.
+type Rule struct {
+	Pattern string
+	Owners  []string
+}
+
+// Match reports whether a file path, relative to the repository root, matches the rule's pattern.
+// Patterns follow the gitignore rules used by CODEOWNERS: patterns without a slash match at any depth,
+// and patterns matching a directory match all the files under it.
+func (r Rule) Match(file string) bool {
+	pattern := r.Pattern
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	if strings.Contains(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else {
+		pattern = "**/" + pattern
+	}
+
+	if !dirOnly && utils.MatchGlob(pattern, file) {
+		return true
+	}
+
+	// A wildcard in the last segment (e.g. "docs/*") only matches files in that directory, not in subdirectories
+	lastSegment := pattern[strings.LastIndex(pattern, "/")+1:]
+	if strings.Contains(lastSegment, "*") && lastSegment != "**" {
+		return false
+	}
+
+	return utils.MatchGlob(pattern+"/**", file)
+}
+
+// Load finds and parses the repository's CODEOWNERS file.
+// It returns os.ErrNotExist if the repository has no CODEOWNERS file.
+func Load() ([]Rule, error) {
+	for _, path := range Paths {
+		fullPath, err := utils.FindRelativePathInRepo(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, errors.Wrap(err, "Failed to find CODEOWNERS file")
+		}
+
+		content, err := utils.ReadFile(fullPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return Parse(string(content)), nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+// Parse parses the rules of a CODEOWNERS file.
+func Parse(content string) []Rule {
+	rules := []Rule{}
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rules = append(rules, Rule{Pattern: fields[0], Owners: fields[1:]})
+	}
+
+	return rules
+}
+
+// Owners returns the owners of the given files. For each file, the last matching rule takes precedence.
+func Owners(rules []Rule, files []string) []string {
+	owners := []string{}
+	for _, file := range files {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].Match(file) {
+				owners = append(owners, rules[i].Owners...)
+
+				break
+			}
+		}
+	}
+
+	return lo.Uniq(owners)
+}
@@ -0,0 +1,67 @@
+package codeowners_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/codeowners"
+)
+
+const content = `# Default owners
+*       @org/everyone
+
+*.js    @js-owner # JavaScript files
+/docs/  @docs-owner
+apps/   @apps-owner
+/build/logs/ @logs-owner
+config/* @config-owner
+/infra  @org/platform user@example.com
+`
+
+func Test_Parse(t *testing.T) {
+	rules := codeowners.Parse(content)
+
+	assert.Len(t, rules, 7)
+	assert.Equal(t, codeowners.Rule{Pattern: "*.js", Owners: []string{"@js-owner"}}, rules[1])
+	assert.Equal(t, codeowners.Rule{Pattern: "/infra", Owners: []string{"@org/platform", "user@example.com"}}, rules[6])
+}
+
+func Test_Owners(t *testing.T) {
+	rules := codeowners.Parse(content)
+
+	tests := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{name: "default owners", files: []string{"main.go"}, expected: []string{"@org/everyone"}},
+		{name: "extension at any depth", files: []string{"web/src/app.js"}, expected: []string{"@js-owner"}},
+		{name: "anchored directory", files: []string{"docs/guides/setup.md"}, expected: []string{"@docs-owner"}},
+		{name: "unanchored directory", files: []string{"src/apps/main.go"}, expected: []string{"@apps-owner"}},
+		{name: "last matching rule wins", files: []string{"apps/web/app.js"}, expected: []string{"@apps-owner"}},
+		{name: "nested directory", files: []string{"build/logs/out.log"}, expected: []string{"@logs-owner"}},
+		{name: "directory wildcard", files: []string{"config/app.yaml"}, expected: []string{"@config-owner"}},
+		{
+			name:     "directory wildcard excludes subdirectories",
+			files:    []string{"config/env/prod.yaml"},
+			expected: []string{"@org/everyone"},
+		},
+		{
+			name:     "path without trailing slash",
+			files:    []string{"infra/main.tf"},
+			expected: []string{"@org/platform", "user@example.com"},
+		},
+		{
+			name:     "multiple files",
+			files:    []string{"main.go", "docs/README.md", "docs/index.md"},
+			expected: []string{"@org/everyone", "@docs-owner"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, codeowners.Owners(rules, test.files))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/models"
+)
+
+// OtherType is the group type of commits that don't follow the Conventional Commits specification.
+const OtherType = "other"
+
+var (
+	headerMatcher         = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+	breakingFooterMatcher = regexp.MustCompile(`^BREAKING[ -]CHANGE:\s*(.*)$`)
+
+	// TypeTitles are the human-readable titles of the commit types, in the order their groups are rendered.
+	TypeTitles = []lo.Entry[string, string]{
+		{Key: "feat", Value: "Features"},
+		{Key: "fix", Value: "Bug Fixes"},
+		{Key: "perf", Value: "Performance Improvements"},
+		{Key: "refactor", Value: "Code Refactoring"},
+		{Key: "revert", Value: "Reverts"},
+		{Key: "docs", Value: "Documentation"},
+		{Key: "test", Value: "Tests"},
+		{Key: "build", Value: "Build System"},
+		{Key: "ci", Value: "Continuous Integration"},
+		{Key: "style", Value: "Styles"},
+		{Key: "chore", Value: "Chores"},
+	}
+)
+
+// Conventional is a commit message parsed according to the Conventional Commits specification.
+// See https://www.conventionalcommits.org.
+type Conventional struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+	// BreakingChange is the description of the breaking change, from the BREAKING CHANGE footer.
+	BreakingChange string
+}
+
+// ParseConventional parses a commit message. Returns false if it doesn't follow the Conventional Commits specification.
+func ParseConventional(subject string, body string) (Conventional, bool) {
+	matches := headerMatcher.FindStringSubmatch(strings.TrimSpace(subject))
+	if len(matches) == 0 {
+		return Conventional{}, false
+	}
+
+	c := Conventional{
+		Type:        strings.ToLower(matches[1]),
+		Scope:       strings.TrimSpace(matches[2]),
+		Description: strings.TrimSpace(matches[4]),
+		Breaking:    matches[3] == "!",
+	}
+
+	breakingChangeLines := []string{}
+	inBreakingChange := false
+	for _, line := range strings.Split(body, "\n") {
+		if footer := breakingFooterMatcher.FindStringSubmatch(line); len(footer) > 0 {
+			inBreakingChange = true
+			c.Breaking = true
+			breakingChangeLines = append(breakingChangeLines, footer[1])
+
+			continue
+		}
+
+		if inBreakingChange {
+			if strings.TrimSpace(line) == "" {
+				break
+			}
+			breakingChangeLines = append(breakingChangeLines, line)
+		}
+	}
+	c.BreakingChange = strings.TrimSpace(strings.Join(breakingChangeLines, "\n"))
+
+	return c, true
+}
+
+// Entry is a commit in a group.
+type Entry struct {
+	models.Commit
+	Conventional
+}
+
+// Group is a group of commits of the same type.
+type Group struct {
+	Type    string
+	Title   string
+	Commits []Entry
+}
+
+// GroupCommits groups commits by their Conventional Commits type. Commits that don't follow the specification
+// are grouped under OtherType. Also returns the breaking changes introduced by the commits.
+func GroupCommits(commits []models.Commit) ([]Group, []string) {
+	entriesByType := map[string][]Entry{}
+	types := []string{}
+	breakingChanges := []string{}
+
+	for _, c := range commits {
+		conventional, ok := ParseConventional(c.Subject, c.Body)
+		if !ok {
+			conventional = Conventional{Type: OtherType, Description: strings.TrimSpace(c.Subject)}
+		}
+
+		if conventional.Breaking {
+			breakingChange := conventional.BreakingChange
+			if breakingChange == "" {
+				breakingChange = conventional.Description
+			}
+			breakingChanges = append(breakingChanges, breakingChange)
+		}
+
+		if _, ok := entriesByType[conventional.Type]; !ok {
+			types = append(types, conventional.Type)
+		}
+		entry := Entry{Commit: c, Conventional: conventional}
+		entriesByType[conventional.Type] = append(entriesByType[conventional.Type], entry)
+	}
+
+	groups := []Group{}
+	for _, t := range TypeTitles {
+		if entries, ok := entriesByType[t.Key]; ok {
+			groups = append(groups, Group{Type: t.Key, Title: t.Value, Commits: entries})
+		}
+	}
+
+	// Unknown types are rendered after the known ones, in order of appearance, followed by other commits
+	for _, t := range types {
+		if t == OtherType || lo.ContainsBy(TypeTitles, func(e lo.Entry[string, string]) bool { return e.Key == t }) {
+			continue
+		}
+		groups = append(groups, Group{Type: t, Title: t, Commits: entriesByType[t]})
+	}
+	if entries, ok := entriesByType[OtherType]; ok {
+		groups = append(groups, Group{Type: OtherType, Title: "Other Changes", Commits: entries})
+	}
+
+	return groups, breakingChanges
+}
@@ -0,0 +1,89 @@
+package commit_test
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/commit"
+	"github.com/ilaif/gh-prx/pkg/models"
+)
+
+func Test_ParseConventional(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  string
+		body     string
+		expected commit.Conventional
+		ok       bool
+	}{
+		{
+			name:     "type and description",
+			subject:  "feat: add foo",
+			expected: commit.Conventional{Type: "feat", Description: "add foo"},
+			ok:       true,
+		},
+		{
+			name:     "scope",
+			subject:  "Fix(api): handle nil response",
+			expected: commit.Conventional{Type: "fix", Scope: "api", Description: "handle nil response"},
+			ok:       true,
+		},
+		{
+			name:     "breaking change marker",
+			subject:  "feat(api)!: remove v1 endpoints",
+			expected: commit.Conventional{Type: "feat", Scope: "api", Description: "remove v1 endpoints", Breaking: true},
+			ok:       true,
+		},
+		{
+			name:    "breaking change footer",
+			subject: "refactor: rename config keys",
+			body:    "Some context.\n\nBREAKING CHANGE: `foo` is renamed to `bar`,\nupdate your config.\n\nRefs: #12",
+			expected: commit.Conventional{
+				Type:           "refactor",
+				Description:    "rename config keys",
+				Breaking:       true,
+				BreakingChange: "`foo` is renamed to `bar`,\nupdate your config.",
+			},
+			ok: true,
+		},
+		{
+			name:    "not conventional",
+			subject: "Add foo",
+			ok:      false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, ok := commit.ParseConventional(test.subject, test.body)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, c)
+		})
+	}
+}
+
+func Test_GroupCommits(t *testing.T) {
+	a := assert.New(t)
+
+	groups, breakingChanges := commit.GroupCommits([]models.Commit{
+		{SHA: "1111111111", Author: "alice", Subject: "chore: bump deps"},
+		{SHA: "2222222222", Author: "bob", Subject: "fix(ui): align button"},
+		{SHA: "3333333333", Author: "alice", Subject: "Update README"},
+		{SHA: "4444444444", Author: "bob", Subject: "feat!: drop node 16"},
+		{SHA: "5555555555", Author: "bob", Subject: "wip: try things"},
+		{SHA: "6666666666", Author: "alice", Subject: "fix: handle nil", Body: "BREAKING-CHANGE: errors are returned"},
+	})
+
+	a.Equal([]string{"feat", "fix", "chore", "wip", "other"},
+		lo.Map(groups, func(g commit.Group, _ int) string { return g.Type }))
+	a.Equal([]string{"Features", "Bug Fixes", "Chores", "wip", "Other Changes"},
+		lo.Map(groups, func(g commit.Group, _ int) string { return g.Title }))
+	a.Equal([]string{"align button", "handle nil"},
+		lo.Map(groups[1].Commits, func(e commit.Entry, _ int) string { return e.Description }))
+	a.Equal("ui", groups[1].Commits[0].Scope)
+	a.Equal("2222222", groups[1].Commits[0].ShortSHA())
+	a.Equal("bob", groups[1].Commits[0].Author)
+	a.Equal([]string{"drop node 16", "errors are returned"}, breakingChanges)
+}
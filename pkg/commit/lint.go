@@ -0,0 +1,55 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// generatedSubjectMatcher matches the subjects of commits generated by git (merges, reverts, fixups and squashes),
+// which are not linted.
+var generatedSubjectMatcher = regexp.MustCompile(`^(Merge |Revert "|fixup! |squash! |amend! )`)
+
+// Subject returns the subject (first non-empty line) of a commit message, skipping "#" comment lines like git does.
+func Subject(message string) string {
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
+
+// Lint checks that a commit subject follows the Conventional Commits specification, with one of types
+// (any type if empty).
+func Lint(subject string, types []string) error {
+	if subject == "" {
+		return errors.New("Commit message is empty")
+	}
+
+	if generatedSubjectMatcher.MatchString(subject) {
+		return nil
+	}
+
+	c, ok := ParseConventional(subject, "")
+	if !ok {
+		return errors.Errorf(
+			"Commit subject '%s' doesn't follow Conventional Commits: expected '<type>[(<scope>)][!]: <description>'",
+			subject,
+		)
+	}
+
+	if len(types) > 0 && !lo.Contains(types, c.Type) {
+		return errors.Errorf("Commit subject '%s' has an invalid type '%s': should be one of %s",
+			subject, c.Type, strings.Join(types, ", "),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package commit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/commit"
+)
+
+func Test_Subject(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("feat: Add foo", commit.Subject("\n# Please enter the commit message\nfeat: Add foo\n\nBody\n"))
+	a.Equal("", commit.Subject("# Only comments\n\n"))
+}
+
+func Test_Lint(t *testing.T) {
+	types := []string{"fix", "feat"}
+	tests := []struct {
+		subject string
+		err     bool
+	}{
+		{subject: "feat: Add foo"},
+		{subject: "fix(api)!: Drop bar"},
+		{subject: "Merge branch 'main' into feat/add-foo"},
+		{subject: `Revert "feat: Add foo"`},
+		{subject: "fixup! feat: Add foo"},
+		{subject: "Add foo", err: true},
+		{subject: "chore: Update deps", err: true},
+		{subject: "feat:Add foo", err: true},
+		{subject: "", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			a := assert.New(t)
+
+			err := commit.Lint(test.subject, types)
+			if test.err {
+				a.Error(err)
+			} else {
+				a.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/models"
+)
+
+// CoAuthorTrailer is the trailer GitHub uses to attribute a commit to multiple authors.
+const CoAuthorTrailer = "Co-authored-by"
+
+var trailerMatcher = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*)$`)
+
+// ParseTrailers parses the trailers of a commit message body, i.e. the "Key: Value" lines of its last paragraph.
+// Like git interpret-trailers, the last paragraph is only considered trailers if all of its lines are trailers
+// (or continuation lines, starting with whitespace).
+func ParseTrailers(body string) []models.Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(body, "\r\n", "\n")), "\n\n")
+	lastParagraph := paragraphs[len(paragraphs)-1]
+	if lastParagraph == "" {
+		return nil
+	}
+
+	trailers := []models.Trailer{}
+	for _, line := range strings.Split(lastParagraph, "\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if len(trailers) == 0 {
+				return nil
+			}
+			trailers[len(trailers)-1].Value += " " + strings.TrimSpace(line)
+
+			continue
+		}
+
+		matches := trailerMatcher.FindStringSubmatch(line)
+		if len(matches) == 0 {
+			return nil
+		}
+		trailers = append(trailers, models.Trailer{Key: matches[1], Value: strings.TrimSpace(matches[2])})
+	}
+
+	return trailers
+}
+
+// CoAuthors returns the unique co-authors of the commits (e.g. "Jane Doe <jane@example.com>"), from their
+// Co-authored-by trailers, in order of appearance.
+func CoAuthors(commits []models.Commit) []string {
+	coAuthors := []string{}
+	for _, c := range commits {
+		coAuthors = append(coAuthors, c.TrailerValues(CoAuthorTrailer)...)
+	}
+
+	return lo.UniqBy(coAuthors, strings.ToLower)
+}
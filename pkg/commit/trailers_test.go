@@ -0,0 +1,59 @@
+package commit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/commit"
+	"github.com/ilaif/gh-prx/pkg/models"
+)
+
+func Test_ParseTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []models.Trailer
+	}{
+		{
+			name: "trailers",
+			body: "Some context.\n\nRefs: #12\nCo-authored-by: Jane Doe\n  <jane@example.com>",
+			expected: []models.Trailer{
+				{Key: "Refs", Value: "#12"},
+				{Key: "Co-authored-by", Value: "Jane Doe <jane@example.com>"},
+			},
+		},
+		{
+			name:     "only trailers",
+			body:     "Signed-off-by: John Doe <john@example.com>",
+			expected: []models.Trailer{{Key: "Signed-off-by", Value: "John Doe <john@example.com>"}},
+		},
+		{
+			name: "last paragraph is not trailers",
+			body: "Refs: #12\n\nNote: this is not a trailer\nbecause of this line.",
+		},
+		{
+			name: "empty body",
+			body: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, commit.ParseTrailers(test.body))
+		})
+	}
+}
+
+func Test_CoAuthors(t *testing.T) {
+	coAuthors := commit.CoAuthors([]models.Commit{
+		{Trailers: []models.Trailer{{Key: "Co-authored-by", Value: "Jane Doe <jane@example.com>"}}},
+		{Trailers: []models.Trailer{{Key: "Refs", Value: "#12"}}},
+		{Trailers: []models.Trailer{
+			{Key: "co-authored-by", Value: "Jane Doe <Jane@example.com>"},
+			{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+		}},
+	})
+
+	assert.Equal(t, []string{"Jane Doe <jane@example.com>", "Bob <bob@example.com>"}, coAuthors)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"dario.cat/mergo"
 	"github.com/caarlos0/log"
@@ -18,8 +19,9 @@ import (
 const (
 	DefaultConfigFilepath = ".github/.gh-prx.yaml"
 	DefaultTitle          = "{{.Type}}{{with .Issue}}({{.}}){{end}}: {{humanize .Description}}"
-	DefaultBody           = `{{with .Issue}}Closes #{{.}}.
-
+	DefaultBody           = `{{range .Issues}}{{closes .}}
+{{end}}{{with .RelatedIssues}}Related: {{range $i, $key := .}}{{if $i}}, {{end}}{{issueLink $key}}{{end}}
+{{end}}{{if or .Issues .RelatedIssues}}
 {{end}}## Description
 
 {{if .AISummary}}{{.AISummary}}{{ else }}{{humanize .Description}}
@@ -34,9 +36,100 @@ Change(s) in this PR:
 
 - [ ] Tests are included
 - [ ] Documentation is changed or added
+{{- with .CoAuthors}}
+
+{{range .}}Co-authored-by: {{.}}
+{{end}}{{- end}}
 `
 	DefaultBranchTemplate = "{{.Type}}/{{with .Issue}}{{.}}-{{end}}{{.Description}}"
 	DefaultBranchPattern  = `{{.Type}}\/({{.Issue}}-)?{{.Description}}`
+	DefaultPushRemote     = "origin"
+
+	DefaultMergeMethod = "squash"
+	DefaultMergeTitle  = "{{.Title}} (#{{.Number}})"
+	DefaultMergeBody   = `{{range .Commits}}* {{.Subject}}
+{{end}}{{if or .Issues .RelatedIssues}}
+{{range .Issues}}{{closes .}}
+{{end}}{{with .RelatedIssues}}Related: {{range $i, $key := .}}{{if $i}}, {{end}}{{issueLink $key}}{{end}}
+{{end}}{{end}}{{with .CoAuthors}}
+{{range .}}Co-authored-by: {{.}}
+{{end}}{{end}}`
+
+	// DefaultPullRequestTemplatePath is GitHub's single pull request template.
+	DefaultPullRequestTemplatePath = ".github/pull_request_template.md"
+	// DefaultPullRequestTemplatesDir is GitHub's directory of multiple pull request templates.
+	DefaultPullRequestTemplatesDir = ".github/PULL_REQUEST_TEMPLATE"
+
+	DefaultBackportBranchTemplate = "backport/{{.Target}}/{{.Original}}"
+	DefaultBackportTitle          = "{{.OriginalTitle}} (backport #{{.Number}} to {{.Target}})"
+	DefaultBackportBody           = "Backport of #{{.Number}} to `{{.Target}}`.\n\n{{.OriginalBody}}"
+
+	DefaultChangelogPath     = "CHANGELOG.md"
+	DefaultChangelogTemplate = `## {{.Version}} ({{.Date}})
+{{range .Sections}}
+### {{.Title}}
+
+{{range .Entries}}* {{with .Scope}}**{{.}}:** {{end}}{{.Description}} ([#{{.Number}}]({{.URL}})) by @{{.Author}}
+{{end}}{{end}}`
+
+	DefaultAIModel               = "gpt-3.5-turbo"
+	DefaultAITimeout             = 60 * time.Second
+	DefaultAISummarySystemPrompt = "You are a code reviewer. " +
+		"You are summarizing a pull request according to the code changes. " +
+		"You like making descriptions short and to the point."
+	DefaultAISummaryUserPrompt = `Please summarize the pull request changes.
+
+The git diff output for the PR:
+'''
+{{.Diff}}
+'''
+
+Structure your answer to conform with the following template:
+'''
+{{.PRTemplate}}
+'''
+
+Please follow these guidelines:
+- Do not repeat the commit summaries or the file summaries.
+- Mention the file names that were changed, if applicable.
+- Prefer bullet points over long sentences.
+`
+	DefaultAIReviewSystemPrompt = "You are a senior code reviewer. " +
+		"You review pull requests and point out bugs, security issues and unclear code. " +
+		"You only report actual issues and keep your comments short and actionable."
+	DefaultAIReviewUserPrompt = `Please review the pull request changes.
+
+The git diff output for the PR:
+'''
+{{.Diff}}
+'''
+
+Respond with a JSON array of findings and nothing else. Each finding is an object with the following fields:
+- "file": The path of the changed file.
+- "line": The line number in the new version of the file.
+- "severity": One of "error", "warning" or "info".
+- "message": A short description of the issue and how to fix it.
+
+Respond with an empty JSON array if there are no issues.
+`
+	DefaultAIChecklistSystemPrompt = "You are a code reviewer. " +
+		"You are answering a pull request checklist according to the code changes. " +
+		"You only answer yes when the changes clearly satisfy a checklist item."
+	DefaultAIChecklistUserPrompt = `Please answer the pull request checklist according to the changes.
+
+The git diff output for the PR:
+'''
+{{.Diff}}
+'''
+
+The checklist items:
+{{range $i, $q := .Questions}}{{$i}}. {{$q}}
+{{end}}
+Respond with a JSON array and nothing else, with one object per checklist item with the following fields:
+- "index": The number of the checklist item, as given above.
+- "answer": "yes" if the item is satisfied, "no" if it is relevant but not satisfied, or "skip" if it is not relevant.
+- "reason": A one-line justification for the answer.
+`
 )
 
 var (
@@ -54,6 +147,27 @@ var (
 	Providers              = []string{"github", "jira", "linear"}
 	DefaultProvider        = "github"
 	ErrInvalidProvider     = errors.New("Invalid provider")
+	MergeMethods           = []string{"squash", "merge", "rebase"}
+
+	DefaultChangelogSections = []ChangelogSectionConfig{
+		{Title: "Features", Types: []string{"feat", "feature"}, Labels: []string{"enhancement"}},
+		{Title: "Bug Fixes", Types: []string{"fix"}, Labels: []string{"bug"}},
+		{Title: "Performance Improvements", Types: []string{"perf"}},
+		{Title: "Documentation", Types: []string{"docs"}, Labels: []string{"documentation"}},
+	}
+	DefaultChangelogExcludeLabels = []string{"skip-changelog"}
+	DefaultBackportLabels         = []string{"backport"}
+	DefaultReleaseMinorTypes      = []string{"feat", "feature"}
+	DefaultReleaseMajorLabels     = []string{"breaking-change"}
+	DefaultLintIgnoreBranches     = []string{"main", "master", "develop", "backport/**"}
+
+	DefaultTypeLabels = map[string][]string{
+		"fix":     {"bug"},
+		"feat":    {"enhancement"},
+		"feature": {"enhancement"},
+		"docs":    {"documentation"},
+		"task":    {"chore"},
+	}
 )
 
 type RepositoryConfig struct {
@@ -61,7 +175,17 @@ type RepositoryConfig struct {
 	PR                      PullRequestConfig `yaml:"pr"`
 	Issue                   IssueConfig       `yaml:"issue"`
 	CheckoutNew             CheckoutNewConfig `yaml:"checkout_new"`
+	AI                      AIConfig          `yaml:"ai"`
+	Merge                   MergeConfig       `yaml:"merge"`
+	Changelog               ChangelogConfig   `yaml:"changelog"`
+	Release                 ReleaseConfig     `yaml:"release"`
+	Backport                BackportConfig    `yaml:"backport"`
+	Lint                    LintConfig        `yaml:"lint"`
 	PullRequestTemplatePath string            `yaml:"pull_request_template_path"`
+	// PullRequestTemplatesDir is a directory of multiple pull request templates.
+	PullRequestTemplatesDir string `yaml:"pull_request_templates_dir"`
+	// PullRequestTemplates maps a branch type to a template file in PullRequestTemplatesDir.
+	PullRequestTemplates map[string]string `yaml:"pull_request_templates"`
 }
 
 func (c *RepositoryConfig) SetDefaults() {
@@ -69,10 +193,25 @@ func (c *RepositoryConfig) SetDefaults() {
 	c.PR.SetDefaults()
 	c.Issue.SetDefaults()
 	c.CheckoutNew.SetDefaults()
+	c.AI.SetDefaults()
+	c.Merge.SetDefaults()
+	c.Changelog.SetDefaults()
+	c.Release.SetDefaults()
+	c.Backport.SetDefaults()
+	c.Lint.SetDefaults()
 
 	if c.PullRequestTemplatePath == "" {
-		c.PullRequestTemplatePath = ".github/pull_request_template.md"
+		c.PullRequestTemplatePath = DefaultPullRequestTemplatePath
 	}
+
+	if c.PullRequestTemplatesDir == "" {
+		c.PullRequestTemplatesDir = DefaultPullRequestTemplatesDir
+	}
+
+	// Types are matched case-insensitively
+	c.PullRequestTemplates = lo.MapKeys(c.PullRequestTemplates, func(_ string, t string) string {
+		return strings.ToLower(t)
+	})
 }
 
 func (c *RepositoryConfig) Validate() error {
@@ -86,6 +225,14 @@ func (c *RepositoryConfig) Validate() error {
 		merr = multierror.Append(merr, errors.Wrap(err, "issue"))
 	}
 
+	if err := c.PR.Validate(); err != nil {
+		merr = multierror.Append(merr, errors.Wrap(err, "pr"))
+	}
+
+	if err := c.Merge.Validate(); err != nil {
+		merr = multierror.Append(merr, errors.Wrap(err, "merge"))
+	}
+
 	if err := merr.ErrorOrNil(); err != nil {
 		return errors.Wrap(err, "Invalid repository config")
 	}
@@ -96,6 +243,10 @@ func (c *RepositoryConfig) Validate() error {
 type IssueConfig struct {
 	Provider string   `yaml:"provider"`
 	Types    []string `yaml:"types"`
+	// LinearWorkspace is the Linear workspace URL key, used to link Linear issues (https://linear.app/<workspace>).
+	LinearWorkspace string `yaml:"linear_workspace"`
+	// ProjectKeys are the Jira or Linear project keys (e.g. PROJ) whose issues are matched in commit subjects.
+	ProjectKeys []string `yaml:"project_keys"`
 }
 
 func (c *IssueConfig) SetDefaults() {
@@ -190,6 +341,14 @@ type PullRequestConfig struct {
 	IgnoreCommitsPatterns []string `yaml:"ignore_commits_patterns"`
 	AnswerChecklist       *bool    `yaml:"answer_checklist"`
 	PushToRemote          *bool    `yaml:"push_to_remote"`
+	PushRemote            string   `yaml:"push_remote"`
+	// TypeLabels maps a branch type to the labels to add to the PR. Types without a mapping are used as the label.
+	TypeLabels   map[string][]string    `yaml:"type_labels"`
+	Labels       map[string]LabelConfig `yaml:"labels"`
+	CreateLabels *bool                  `yaml:"create_labels"`
+	PathRules    []PathRuleConfig       `yaml:"path_rules"`
+	// CodeownersReviewers enables suggesting the CODEOWNERS of the changed files as reviewers.
+	CodeownersReviewers *bool `yaml:"codeowners_reviewers"`
 
 	Body string `yaml:"-"`
 }
@@ -216,6 +375,53 @@ func (c *PullRequestConfig) SetDefaults() {
 		trueVal := true
 		c.PushToRemote = &trueVal
 	}
+
+	if c.PushRemote == "" {
+		c.PushRemote = DefaultPushRemote
+	}
+
+	if c.TypeLabels == nil {
+		c.TypeLabels = DefaultTypeLabels
+	} else {
+		// Types are matched case-insensitively
+		c.TypeLabels = lo.MapKeys(c.TypeLabels, func(_ []string, t string) string { return strings.ToLower(t) })
+	}
+
+	if c.CreateLabels == nil {
+		trueVal := true
+		c.CreateLabels = &trueVal
+	}
+
+	if c.CodeownersReviewers == nil {
+		falseVal := false
+		c.CodeownersReviewers = &falseVal
+	}
+}
+
+func (c *PullRequestConfig) Validate() error {
+	for i, rule := range c.PathRules {
+		if len(rule.Paths) == 0 {
+			return errors.Errorf("path_rules[%d]: paths must not be empty", i)
+		}
+	}
+
+	return nil
+}
+
+// PathRuleConfig adds labels, reviewers, assignees and projects to PRs that change files matching any of its paths.
+// Paths are glob patterns relative to the repository root, where "**" matches any number of directories.
+type PathRuleConfig struct {
+	Paths     []string `yaml:"paths"`
+	Labels    []string `yaml:"labels"`
+	Reviewers []string `yaml:"reviewers"`
+	Assignees []string `yaml:"assignees"`
+	Projects  []string `yaml:"projects"`
+}
+
+// LabelConfig defines how a label is created (or updated, if it already exists).
+type LabelConfig struct {
+	Color       string `yaml:"color"` // Hex color, without the leading "#"
+	Description string `yaml:"description"`
 }
 
 type CheckoutNewConfig struct {
@@ -252,6 +458,229 @@ func (c *CheckoutNewGitHubConfig) SetDefaults() {
 	}
 }
 
+type MergeConfig struct {
+	// The merge method: squash, merge or rebase.
+	Method string `yaml:"method"`
+	// The templates of the merge commit title and body, with access to the branch fields, the pull request
+	// {{.Title}}, {{.Number}} and {{.URL}}, {{.Commits}}, {{.CommitGroups}}, {{.BreakingChanges}}, {{.Issues}}
+	// and {{.CoAuthors}}. Not used by the rebase method.
+	Title string `yaml:"title"`
+	Body  string `yaml:"body"`
+	// Whether to enable auto-merge, merging the pull request once its requirements are met.
+	AutoMerge *bool `yaml:"auto_merge"`
+	// Whether to delete the local and remote branch after merging.
+	DeleteBranch *bool `yaml:"delete_branch"`
+}
+
+func (c *MergeConfig) SetDefaults() {
+	if c.Method == "" {
+		c.Method = DefaultMergeMethod
+	}
+
+	if c.Title == "" {
+		c.Title = DefaultMergeTitle
+	}
+
+	if c.Body == "" {
+		c.Body = DefaultMergeBody
+	}
+
+	if c.AutoMerge == nil {
+		falseVal := false
+		c.AutoMerge = &falseVal
+	}
+
+	if c.DeleteBranch == nil {
+		trueVal := true
+		c.DeleteBranch = &trueVal
+	}
+}
+
+func (c *MergeConfig) Validate() error {
+	if !lo.Contains(MergeMethods, c.Method) {
+		return errors.Errorf("method: Invalid merge method '%s': Should be one of %s",
+			c.Method, strings.Join(MergeMethods, ", "),
+		)
+	}
+
+	return nil
+}
+
+type ChangelogConfig struct {
+	// The changelog template, with access to {{.Version}}, {{.Date}}, {{.From}}, {{.To}}, {{.Sections}}
+	// and {{.Entries}}.
+	Template string `yaml:"template"`
+	// The sections merged pull requests are grouped into, in order. A pull request is added to the first section
+	// matching its type or labels, or to the "Other Changes" section if none match.
+	Sections []ChangelogSectionConfig `yaml:"sections"`
+	// Pull requests with any of these labels are left out of the changelog.
+	ExcludeLabels []string `yaml:"exclude_labels"`
+	// The changelog file the changelog is prepended to, relative to the repository root.
+	Path string `yaml:"path"`
+}
+
+func (c *ChangelogConfig) SetDefaults() {
+	if c.Template == "" {
+		c.Template = DefaultChangelogTemplate
+	}
+
+	if c.Sections == nil {
+		c.Sections = DefaultChangelogSections
+	}
+
+	if c.ExcludeLabels == nil {
+		c.ExcludeLabels = DefaultChangelogExcludeLabels
+	}
+
+	if c.Path == "" {
+		c.Path = DefaultChangelogPath
+	}
+}
+
+// ChangelogSectionConfig is a changelog section of the pull requests of any of Types or with any of Labels.
+type ChangelogSectionConfig struct {
+	Title  string   `yaml:"title"`
+	Types  []string `yaml:"types"`
+	Labels []string `yaml:"labels"`
+}
+
+type ReleaseConfig struct {
+	// The types of the pull requests that bump the minor version. Other types bump the patch version.
+	MinorTypes []string `yaml:"minor_types"`
+	// The labels of the pull requests that bump the major version, in addition to breaking changes marked by a "!"
+	// after the type in the pull request title (e.g. "feat!: Drop node 16").
+	MajorLabels []string `yaml:"major_labels"`
+	// Whether to create a GitHub release with the changelog as its notes, in addition to the tag.
+	GitHubRelease *bool `yaml:"github_release"`
+}
+
+func (c *ReleaseConfig) SetDefaults() {
+	if c.MinorTypes == nil {
+		c.MinorTypes = DefaultReleaseMinorTypes
+	}
+
+	if c.MajorLabels == nil {
+		c.MajorLabels = DefaultReleaseMajorLabels
+	}
+
+	if c.GitHubRelease == nil {
+		falseVal := false
+		c.GitHubRelease = &falseVal
+	}
+}
+
+type BackportConfig struct {
+	// The templates of the backport branch name, and of the backport pull request title and body.
+	// They have access to the original branch fields (e.g. {{.Type}}, {{.Issue}}), {{.Original}} (the original
+	// branch name), {{.Number}}, {{.OriginalTitle}}, {{.OriginalBody}}, {{.OriginalURL}} and {{.Target}}
+	// (the target branch). The title and body templates also have access to the PR body variables (e.g. {{.Commits}}).
+	BranchTemplate string `yaml:"branch_template"`
+	Title          string `yaml:"title"`
+	Body           string `yaml:"body"`
+	// The labels to add to the backport pull request.
+	Labels []string `yaml:"labels"`
+}
+
+func (c *BackportConfig) SetDefaults() {
+	if c.BranchTemplate == "" {
+		c.BranchTemplate = DefaultBackportBranchTemplate
+	}
+
+	if c.Title == "" {
+		c.Title = DefaultBackportTitle
+	}
+
+	if c.Body == "" {
+		c.Body = DefaultBackportBody
+	}
+
+	if c.Labels == nil {
+		c.Labels = DefaultBackportLabels
+	}
+}
+
+type LintConfig struct {
+	// The branches that are not linted (e.g. long-lived branches), as glob patterns (e.g. "release/*").
+	IgnoreBranches []string `yaml:"ignore_branches"`
+	// The regular expression PR titles should match. By default, it's derived from the PR title template.
+	TitlePattern string `yaml:"title_pattern"`
+}
+
+func (c *LintConfig) SetDefaults() {
+	if c.IgnoreBranches == nil {
+		c.IgnoreBranches = DefaultLintIgnoreBranches
+	}
+}
+
+type AIConfig struct {
+	// The OpenAI model to use.
+	Model string `yaml:"model"`
+
+	// The maximum time to wait for a single AI request, e.g. "30s" or "2m".
+	Timeout time.Duration `yaml:"timeout"`
+
+	// The prompts used to summarize a pull request.
+	// The prompts are Go templates with access to the branch fields (e.g. {{.Type}}, {{.Issue}}),
+	// {{.Diff}}, {{.Commits}} (subjects), {{.CommitMessages}} (full messages), {{.PRTemplate}} and {{.IssueTitle}}.
+	Summary AIPromptConfig `yaml:"summary"`
+
+	// The prompts used by the review command, with access to the branch fields, {{.Diff}}, {{.Commits}}
+	// and {{.CommitMessages}}.
+	// The model is expected to respond with a JSON array of findings.
+	Review AIPromptConfig `yaml:"review"`
+
+	// Whether to propose answers to the PR checklist using AI.
+	AnswerChecklist *bool `yaml:"answer_checklist"`
+
+	// The prompts used to answer the PR checklist, with access to the branch fields, {{.Diff}}, {{.Commits}},
+	// {{.CommitMessages}} and {{.Questions}}. The model is expected to respond with a JSON array of answers.
+	Checklist AIPromptConfig `yaml:"checklist"`
+}
+
+func (c *AIConfig) SetDefaults() {
+	if c.Model == "" {
+		c.Model = DefaultAIModel
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = DefaultAITimeout
+	}
+
+	if c.Summary.SystemPrompt == "" {
+		c.Summary.SystemPrompt = DefaultAISummarySystemPrompt
+	}
+
+	if c.Summary.UserPrompt == "" {
+		c.Summary.UserPrompt = DefaultAISummaryUserPrompt
+	}
+
+	if c.Review.SystemPrompt == "" {
+		c.Review.SystemPrompt = DefaultAIReviewSystemPrompt
+	}
+
+	if c.Review.UserPrompt == "" {
+		c.Review.UserPrompt = DefaultAIReviewUserPrompt
+	}
+
+	if c.AnswerChecklist == nil {
+		falseVal := false
+		c.AnswerChecklist = &falseVal
+	}
+
+	if c.Checklist.SystemPrompt == "" {
+		c.Checklist.SystemPrompt = DefaultAIChecklistSystemPrompt
+	}
+
+	if c.Checklist.UserPrompt == "" {
+		c.Checklist.UserPrompt = DefaultAIChecklistUserPrompt
+	}
+}
+
+type AIPromptConfig struct {
+	SystemPrompt string `yaml:"system_prompt"`
+	UserPrompt   string `yaml:"user_prompt"`
+}
+
 func LoadRepositoryConfig(globalRepoConfig *RepositoryConfig) (*RepositoryConfig, error) {
 	cfg := &RepositoryConfig{}
 
@@ -146,6 +146,16 @@ func SaveSetupConfig(cfg *SetupConfig) error {
 	return nil
 }
 
+// GetCacheDir returns the directory used to cache data across runs (e.g. AI-powered summaries).
+func GetCacheDir() (string, error) {
+	cfgDir, err := getSetupConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(cfgDir, "cache"), nil
+}
+
 func getSetupConfigDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
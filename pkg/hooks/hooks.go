@@ -0,0 +1,115 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/caarlos0/log"
+	"github.com/pkg/errors"
+
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+// Marker marks the git hooks installed by gh prx, so they can be overwritten and uninstalled safely.
+const Marker = "# Installed by gh prx hooks install"
+
+// scripts are the git hooks scripts, by hook name.
+var scripts = map[string]string{
+	"pre-push": heredoc.Doc(`
+		#!/bin/sh
+		` + Marker + `
+		# Lints the names of the pushed branches.
+		while read -r local_ref _local_sha _remote_ref _remote_sha; do
+			case "$local_ref" in
+			refs/heads/*)
+				gh prx lint branch "${local_ref#refs/heads/}" || exit 1
+				;;
+			esac
+		done
+	`),
+	"commit-msg": heredoc.Doc(`
+		#!/bin/sh
+		` + Marker + `
+		# Lints the commit message.
+		exec gh prx lint commit "$1"
+	`),
+}
+
+// Names are the names of the git hooks installed by gh prx.
+var Names = []string{"pre-push", "commit-msg"}
+
+// Install writes the git hooks to hooksDir. Existing hooks that weren't installed by gh prx are only overwritten
+// with force.
+func Install(hooksDir string, force bool) error {
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return errors.Wrapf(err, "Failed to create hooks directory '%s'", hooksDir)
+	}
+
+	for _, name := range Names {
+		path := filepath.Join(hooksDir, name)
+		installed, err := IsInstalled(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err == nil && !installed && !force {
+			return errors.Errorf("Hook '%s' already exists, run with --force to overwrite it", path)
+		}
+
+		if err := os.WriteFile(path, []byte(scripts[name]), 0o755); err != nil { // nolint:gosec
+			return errors.Wrapf(err, "Failed to write hook '%s'", path)
+		}
+		log.Infof("Installed hook '%s'", path)
+	}
+
+	return nil
+}
+
+// Uninstall removes the git hooks installed by gh prx from hooksDir, keeping any other hook.
+func Uninstall(hooksDir string) error {
+	for _, name := range Names {
+		path := filepath.Join(hooksDir, name)
+		installed, err := IsInstalled(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return err
+		}
+		if !installed {
+			log.Warnf("Hook '%s' wasn't installed by gh prx, skipping", path)
+
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return errors.Wrapf(err, "Failed to remove hook '%s'", path)
+		}
+		log.Infof("Removed hook '%s'", path)
+	}
+
+	return nil
+}
+
+// Dir returns the git hooks directory of the repository at repoDir, respecting core.hooksPath.
+func Dir(repoDir string) (string, error) {
+	out, err := utils.Exec("git", "-C", repoDir, "rev-parse", "--path-format=absolute", "--git-path", "hooks")
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to find the git hooks directory")
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// IsInstalled returns whether the hook at path was installed by gh prx.
+// Returns an error wrapping os.ErrNotExist if there is no hook at path.
+func IsInstalled(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to read hook '%s'", path)
+	}
+
+	return strings.Contains(string(content), Marker), nil
+}
@@ -0,0 +1,104 @@
+package hooks_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/hooks"
+)
+
+const foreignHook = "#!/bin/sh\necho foreign\n"
+
+func Test_Install(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      string
+		force         bool
+		expectedErr   bool
+		expectedOwned bool
+	}{
+		{name: "no hooks", expectedOwned: true},
+		{name: "installed hooks", existing: "#!/bin/sh\n" + hooks.Marker + "\n", expectedOwned: true},
+		{name: "foreign hooks", existing: foreignHook, expectedErr: true},
+		{name: "foreign hooks with force", existing: foreignHook, force: true, expectedOwned: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			hooksDir := filepath.Join(t.TempDir(), "hooks")
+			if test.existing != "" {
+				a.NoError(os.MkdirAll(hooksDir, 0o755))
+				for _, name := range hooks.Names {
+					a.NoError(os.WriteFile(filepath.Join(hooksDir, name), []byte(test.existing), 0o755)) // nolint:gosec
+				}
+			}
+
+			err := hooks.Install(hooksDir, test.force)
+			if test.expectedErr {
+				a.Error(err)
+			} else {
+				a.NoError(err)
+			}
+
+			for _, name := range hooks.Names {
+				installed, err := hooks.IsInstalled(filepath.Join(hooksDir, name))
+				a.NoError(err)
+				a.Equal(test.expectedOwned, installed, name)
+			}
+		})
+	}
+}
+
+func Test_Uninstall(t *testing.T) {
+	a := assert.New(t)
+
+	hooksDir := t.TempDir()
+	a.NoError(hooks.Install(hooksDir, false))
+	foreignPath := filepath.Join(hooksDir, "commit-msg")
+	a.NoError(os.WriteFile(foreignPath, []byte(foreignHook), 0o755)) // nolint:gosec
+
+	a.NoError(hooks.Uninstall(hooksDir))
+
+	_, err := hooks.IsInstalled(filepath.Join(hooksDir, "pre-push"))
+	a.ErrorIs(err, os.ErrNotExist)
+	installed, err := hooks.IsInstalled(foreignPath)
+	a.NoError(err)
+	a.False(installed)
+
+	// Hooks that were never installed are skipped
+	a.NoError(hooks.Uninstall(t.TempDir()))
+}
+
+func Test_Dir(t *testing.T) {
+	tests := []struct {
+		name        string
+		hooksPath   string
+		expectedDir string
+	}{
+		{name: "default", expectedDir: filepath.Join(".git", "hooks")},
+		{name: "core.hooksPath", hooksPath: ".githooks", expectedDir: ".githooks"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			repoDir, err := filepath.EvalSymlinks(t.TempDir())
+			a.NoError(err)
+			a.NoError(exec.Command("git", "init", "--quiet", repoDir).Run())
+			if test.hooksPath != "" {
+				a.NoError(exec.Command("git", "-C", repoDir, "config", "core.hooksPath", test.hooksPath).Run())
+			}
+
+			hooksDir, err := hooks.Dir(repoDir)
+			a.NoError(err)
+			a.Equal(filepath.Join(repoDir, test.expectedDir), hooksDir)
+		})
+	}
+}
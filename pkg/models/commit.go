@@ -0,0 +1,69 @@
+package models
+
+import "strings"
+
+const shortSHALength = 7
+
+type Commit struct {
+	SHA         string
+	Author      string
+	AuthorEmail string
+	Subject     string
+	Body        string
+	Trailers    []Trailer
+}
+
+// Trailer is a "Key: Value" line at the end of a commit message (e.g. Co-authored-by, Refs).
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (c Commit) ShortSHA() string {
+	if len(c.SHA) < shortSHALength {
+		return c.SHA
+	}
+
+	return c.SHA[:shortSHALength]
+}
+
+// Message returns the full commit message.
+func (c Commit) Message() string {
+	if c.Body == "" {
+		return c.Subject
+	}
+
+	return c.Subject + "\n\n" + c.Body
+}
+
+// TrailerValues returns the values of the trailers with the given key, case-insensitively.
+func (c Commit) TrailerValues(key string) []string {
+	values := []string{}
+	for _, t := range c.Trailers {
+		if strings.EqualFold(t.Key, key) {
+			values = append(values, t.Value)
+		}
+	}
+
+	return values
+}
+
+// CommitSubjects returns the subjects (first lines) of the commit messages.
+func CommitSubjects(commits []Commit) []string {
+	subjects := make([]string, len(commits))
+	for i, c := range commits {
+		subjects[i] = c.Subject
+	}
+
+	return subjects
+}
+
+// CommitMessages returns the full commit messages.
+func CommitMessages(commits []Commit) []string {
+	messages := make([]string, len(commits))
+	for i, c := range commits {
+		messages[i] = c.Message()
+	}
+
+	return messages
+}
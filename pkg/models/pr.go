@@ -1,7 +1,10 @@
 package models
 
 type PullRequest struct {
-	Title  string
-	Body   string
-	Labels []string
+	Title     string
+	Body      string
+	Labels    []string
+	Reviewers []string
+	Assignees []string
+	Projects  []string
 }
@@ -0,0 +1,39 @@
+package models
+
+// RepoRemotes describes where a branch is pushed to, and which repository its pull request is opened against.
+type RepoRemotes struct {
+	PushRemote string
+	// HeadOwner is the owner of the pushed repository. Only set when it's a fork of BaseRepo.
+	HeadOwner string
+	// BaseRepo is the repository to open the pull request against, in [HOST/]OWNER/REPO format.
+	// Only set when the pushed repository is a fork.
+	BaseRepo string
+	// BaseRemote is the git remote of BaseRepo (e.g. "upstream"), or an empty string if it has none.
+	BaseRemote string
+}
+
+func (r *RepoRemotes) IsFork() bool {
+	return r.BaseRepo != ""
+}
+
+// HeadRef returns the head of a pull request from a branch, in the format expected by gh.
+func (r *RepoRemotes) HeadRef(branchName string) string {
+	if !r.IsFork() {
+		return branchName
+	}
+
+	return r.HeadOwner + ":" + branchName
+}
+
+// FetchSource returns where to fetch the branches of the base repository from: the push remote, the base
+// repository's remote, or its URL if it has no remote.
+func (r *RepoRemotes) FetchSource() string {
+	switch {
+	case !r.IsFork():
+		return r.PushRemote
+	case r.BaseRemote != "":
+		return r.BaseRemote
+	default:
+		return "https://" + r.BaseRepo + ".git"
+	}
+}
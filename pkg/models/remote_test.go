@@ -0,0 +1,52 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/models"
+)
+
+func Test_RepoRemotes(t *testing.T) {
+	tests := []struct {
+		name                string
+		remotes             models.RepoRemotes
+		expectedIsFork      bool
+		expectedHeadRef     string
+		expectedFetchSource string
+	}{
+		{
+			name:                "same repository",
+			remotes:             models.RepoRemotes{PushRemote: "origin"},
+			expectedHeadRef:     "feat/add-foo",
+			expectedFetchSource: "origin",
+		},
+		{
+			name:                "fork",
+			remotes:             models.RepoRemotes{PushRemote: "origin", HeadOwner: "jane", BaseRepo: "github.com/acme/app"},
+			expectedIsFork:      true,
+			expectedHeadRef:     "jane:feat/add-foo",
+			expectedFetchSource: "https://github.com/acme/app.git",
+		},
+		{
+			name: "fork with upstream remote",
+			remotes: models.RepoRemotes{
+				PushRemote: "origin", HeadOwner: "jane", BaseRepo: "github.com/acme/app", BaseRemote: "upstream",
+			},
+			expectedIsFork:      true,
+			expectedHeadRef:     "jane:feat/add-foo",
+			expectedFetchSource: "upstream",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			a.Equal(test.expectedIsFork, test.remotes.IsFork())
+			a.Equal(test.expectedHeadRef, test.remotes.HeadRef("feat/add-foo"))
+			a.Equal(test.expectedFetchSource, test.remotes.FetchSource())
+		})
+	}
+}
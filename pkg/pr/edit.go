@@ -0,0 +1,25 @@
+package pr
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ilaif/gh-prx/pkg/models"
+)
+
+// FormatForEdit renders a PR for editing: the title on the first line, followed by an empty line and the body.
+func FormatForEdit(pr *models.PullRequest) string {
+	return pr.Title + "\n\n" + pr.Body
+}
+
+// ParseEdited parses a PR edited in the FormatForEdit format. The first line is the title, the rest is the body.
+func ParseEdited(content string) (string, string, error) {
+	title, body, _ := strings.Cut(strings.TrimLeft(content, "\n"), "\n")
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", "", errors.New("Pull request title must not be empty")
+	}
+
+	return title, strings.TrimSpace(body), nil
+}
@@ -0,0 +1,26 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+)
+
+func Test_ParseEdited(t *testing.T) {
+	newPR := &models.PullRequest{Title: "feat: Add foo", Body: "## Description\n\nFoo"}
+	title, body, err := pr.ParseEdited(pr.FormatForEdit(newPR))
+	assert.NoError(t, err)
+	assert.Equal(t, "feat: Add foo", title)
+	assert.Equal(t, "## Description\n\nFoo", body)
+
+	title, body, err = pr.ParseEdited("\n  fix: Title only  \n")
+	assert.NoError(t, err)
+	assert.Equal(t, "fix: Title only", title)
+	assert.Equal(t, "", body)
+
+	_, _, err = pr.ParseEdited("\n\n")
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package pr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+)
+
+var (
+	githubIssueRefMatcher = regexp.MustCompile(`(?:^|[^\w/])#(\d+)\b`)
+	issueKeyMatcher       = regexp.MustCompile(`\b(([A-Z][A-Z0-9]*)-\d+)\b`)
+	// pullRequestRefMatcher matches the references to pull requests added to commit subjects by GitHub
+	// (e.g. "Add foo (#45)" or "Merge pull request #45 from ...").
+	pullRequestRefMatcher = regexp.MustCompile(`\(#\d+\)\s*$|^Merge pull request #\d+`)
+
+	// ClosingIssueTrailers are the commit message trailers that reference issues the PR closes (e.g. "Closes: #12").
+	ClosingIssueTrailers = []string{"Closes", "Fixes", "Resolves"}
+	// RelatedIssueTrailers are the commit message trailers that reference issues without closing them
+	// (e.g. "Refs: #12").
+	RelatedIssueTrailers = []string{"Refs", "References", "Issue"}
+)
+
+// IssueLinker renders references to issues according to the issue provider.
+type IssueLinker struct {
+	Provider        string
+	JiraEndpoint    string
+	LinearWorkspace string
+	// ProjectKeys are the Jira or Linear project keys (e.g. "PROJ") whose issues are referenced in commit subjects.
+	ProjectKeys []string
+}
+
+func NewIssueLinker(cfg *config.RepositoryConfig, setupCfg *config.SetupConfig) IssueLinker {
+	projectKeys := append([]string{}, cfg.Issue.ProjectKeys...)
+	if cfg.CheckoutNew.Jira.Project != "" {
+		projectKeys = append(projectKeys, cfg.CheckoutNew.Jira.Project)
+	}
+
+	return IssueLinker{
+		Provider:        cfg.Issue.Provider,
+		JiraEndpoint:    setupCfg.JiraConfig.Endpoint,
+		LinearWorkspace: cfg.Issue.LinearWorkspace,
+		ProjectKeys:     projectKeys,
+	}
+}
+
+// URL returns the URL of an issue, or an empty string if it can't be determined.
+func (l IssueLinker) URL(key string) string {
+	switch l.Provider {
+	case "jira":
+		if l.JiraEndpoint != "" {
+			return fmt.Sprintf("%s/browse/%s", strings.TrimSuffix(l.JiraEndpoint, "/"), key)
+		}
+	case "linear":
+		if l.LinearWorkspace != "" {
+			return fmt.Sprintf("https://linear.app/%s/issue/%s", l.LinearWorkspace, key)
+		}
+	}
+
+	return ""
+}
+
+// Link returns a markdown reference to an issue.
+func (l IssueLinker) Link(key string) string {
+	if l.Provider == "github" {
+		return "#" + strings.TrimPrefix(key, "#")
+	}
+
+	if url := l.URL(key); url != "" {
+		return fmt.Sprintf("[%s](%s)", key, url)
+	}
+
+	return key
+}
+
+// Closes returns a reference to an issue that closes it when the PR is merged.
+func (l IssueLinker) Closes(key string) string {
+	if l.Provider == "linear" {
+		// Linear's magic words are followed by the plain issue key
+		return "Closes " + key
+	}
+
+	return "Closes " + l.Link(key)
+}
+
+// IssueKeys returns the unique keys of the issues the PR closes: the issue of the branch and the issues referenced
+// by the closing trailers of the commit messages (e.g. "Closes: #12"), in that order.
+func (l IssueLinker) IssueKeys(branchIssue string, commits []models.Commit) []string {
+	keys := []string{}
+	if branchIssue != "" {
+		keys = append(keys, l.normalizeKey(branchIssue))
+	}
+
+	return lo.Uniq(append(keys, l.TrailerIssueKeys(commits, ClosingIssueTrailers)...))
+}
+
+// RelatedIssueKeys returns the unique keys of the issues referenced by the related trailers of the commit messages
+// (e.g. "Refs: #12") and the commit subjects that the PR doesn't close (see IssueKeys). For Jira and Linear, only
+// the subject issues of the project keys and of the projects of the branch and trailer issues are matched, so that
+// e.g. "SHA-256" isn't mistaken for an issue.
+func (l IssueLinker) RelatedIssueKeys(branchIssue string, commits []models.Commit) []string {
+	closedKeys := l.IssueKeys(branchIssue, commits)
+	keys := l.TrailerIssueKeys(commits, RelatedIssueTrailers)
+	if matcher := l.subjectKeyMatcher(append(append([]string{}, closedKeys...), keys...)); matcher != nil {
+		for _, c := range commits {
+			subject := pullRequestRefMatcher.ReplaceAllString(c.Subject, "")
+			keys = append(keys, l.findKeys(matcher, subject)...)
+		}
+	}
+
+	return lo.Without(lo.Uniq(keys), closedKeys...)
+}
+
+// TrailerIssueKeys returns the unique keys of the issues referenced by the given trailers of the commit messages.
+func (l IssueLinker) TrailerIssueKeys(commits []models.Commit, trailers []string) []string {
+	matcher := lo.Ternary(l.Provider == "github", githubIssueRefMatcher, issueKeyMatcher)
+
+	keys := []string{}
+	for _, c := range commits {
+		for _, trailer := range trailers {
+			for _, value := range c.TrailerValues(trailer) {
+				if l.Provider == "github" && !strings.Contains(value, "#") {
+					// GitHub issues may be referenced by their plain number in trailers (e.g. "Refs: 12")
+					value = "#" + value
+				}
+				keys = append(keys, l.findKeys(matcher, value)...)
+			}
+		}
+	}
+
+	return lo.Uniq(keys)
+}
+
+// TemplateFunctions returns the issue template functions.
+func (l IssueLinker) TemplateFunctions() template.FuncMap {
+	return template.FuncMap{
+		"issueLink": l.Link,
+		"issueURL":  l.URL,
+		"closes":    l.Closes,
+	}
+}
+
+// subjectKeyMatcher returns the matcher of the issue references in commit subjects, or nil if there are no
+// Jira or Linear projects to match, neither configured nor of the known issue keys.
+func (l IssueLinker) subjectKeyMatcher(knownKeys []string) *regexp.Regexp {
+	if l.Provider == "github" {
+		return githubIssueRefMatcher
+	}
+
+	projectKeys := lo.Map(l.ProjectKeys, func(key string, _ int) string { return strings.ToUpper(key) })
+	for _, key := range knownKeys {
+		if matches := issueKeyMatcher.FindStringSubmatch(key); len(matches) > 0 {
+			projectKeys = append(projectKeys, matches[2])
+		}
+	}
+	if len(projectKeys) == 0 {
+		return nil
+	}
+
+	return regexp.MustCompile(fmt.Sprintf(`\b((?:%s)-\d+)\b`,
+		strings.Join(lo.Map(lo.Uniq(projectKeys), func(key string, _ int) string { return regexp.QuoteMeta(key) }), "|"),
+	))
+}
+
+func (l IssueLinker) findKeys(matcher *regexp.Regexp, s string) []string {
+	return lo.Map(matcher.FindAllStringSubmatch(s, -1), func(matches []string, _ int) string {
+		return l.normalizeKey(matches[1])
+	})
+}
+
+func (l IssueLinker) normalizeKey(key string) string {
+	if l.Provider == "github" {
+		return strings.TrimPrefix(key, "#")
+	}
+
+	// Keys are lower cased in branch names
+	return strings.ToUpper(key)
+}
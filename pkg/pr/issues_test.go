@@ -0,0 +1,153 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+)
+
+func Test_IssueLinker(t *testing.T) {
+	tests := []struct {
+		name            string
+		linker          pr.IssueLinker
+		branchIssue     string
+		commits         []models.Commit
+		expectedKeys    []string
+		expectedRelated []string
+		expectedLink    string
+		expectedCloses  string
+	}{
+		{
+			name:        "github",
+			linker:      pr.IssueLinker{Provider: "github"},
+			branchIssue: "12",
+			commits: []models.Commit{
+				{Subject: "Fix #13 and #12"},
+				{Subject: "See org/repo#14"},
+				{Subject: "Bump to 1.2.3", Trailers: []models.Trailer{{Key: "Closes", Value: "15"}, {Key: "Refs", Value: "16"}}},
+			},
+			expectedKeys:    []string{"12", "15"},
+			expectedRelated: []string{"16", "13"},
+			expectedLink:    "#12",
+			expectedCloses:  "Closes #12",
+		},
+		{
+			name:            "jira",
+			linker:          pr.IssueLinker{Provider: "jira", JiraEndpoint: "https://acme.atlassian.net/"},
+			branchIssue:     "proj-123",
+			commits:         []models.Commit{{Subject: "PROJ-124: Add foo"}, {Subject: "Fix PROJ-123 again"}},
+			expectedKeys:    []string{"PROJ-123"},
+			expectedRelated: []string{"PROJ-124"},
+			expectedLink:    "[PROJ-123](https://acme.atlassian.net/browse/PROJ-123)",
+			expectedCloses:  "Closes [PROJ-123](https://acme.atlassian.net/browse/PROJ-123)",
+		},
+		{
+			name:   "linear",
+			linker: pr.IssueLinker{Provider: "linear", LinearWorkspace: "acme"},
+			commits: []models.Commit{
+				{Subject: "ENG-7 Add foo"},
+				{Subject: "Add bar", Trailers: []models.Trailer{{Key: "fixes", Value: "ENG-8, ENG-9"}}},
+			},
+			expectedKeys:    []string{"ENG-8", "ENG-9"},
+			expectedRelated: []string{"ENG-7"},
+			expectedLink:    "[ENG-8](https://linear.app/acme/issue/ENG-8)",
+			expectedCloses:  "Closes ENG-8",
+		},
+		{
+			name:            "linear without workspace",
+			linker:          pr.IssueLinker{Provider: "linear"},
+			branchIssue:     "eng-7",
+			expectedKeys:    []string{"ENG-7"},
+			expectedRelated: []string{},
+			expectedLink:    "ENG-7",
+			expectedCloses:  "Closes ENG-7",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			keys := test.linker.IssueKeys(test.branchIssue, test.commits)
+			a.Equal(test.expectedKeys, keys)
+			a.Equal(test.expectedRelated, test.linker.RelatedIssueKeys(test.branchIssue, test.commits))
+			a.Equal(test.expectedLink, test.linker.Link(keys[0]))
+			a.Equal(test.expectedCloses, test.linker.Closes(keys[0]))
+		})
+	}
+}
+
+func Test_IssueLinker_RelatedIssueKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		linker      pr.IssueLinker
+		branchIssue string
+		subject     string
+		expected    []string
+	}{
+		{
+			name:     "github squash merge suffix",
+			linker:   pr.IssueLinker{Provider: "github"},
+			subject:  "Fix x (#45)",
+			expected: []string{},
+		},
+		{
+			name:     "github merge commit",
+			linker:   pr.IssueLinker{Provider: "github"},
+			subject:  "Merge pull request #45 from acme/fix-x",
+			expected: []string{},
+		},
+		{
+			name:        "github stray reference",
+			linker:      pr.IssueLinker{Provider: "github"},
+			branchIssue: "12",
+			subject:     "Handle item #3 of the list",
+			expected:    []string{"3"},
+		},
+		{
+			name:        "jira SHA-256",
+			linker:      pr.IssueLinker{Provider: "jira"},
+			branchIssue: "PROJ-1",
+			subject:     "Use SHA-256 for checksums",
+			expected:    []string{},
+		},
+		{
+			name:        "jira UTF-8 and ISO-8601",
+			linker:      pr.IssueLinker{Provider: "jira"},
+			branchIssue: "PROJ-1",
+			subject:     "Parse UTF-8 ISO-8601 dates",
+			expected:    []string{},
+		},
+		{
+			name:        "jira CVE",
+			linker:      pr.IssueLinker{Provider: "jira"},
+			branchIssue: "PROJ-1",
+			subject:     "Fix CVE-2023-1234 in PROJ-2",
+			expected:    []string{"PROJ-2"},
+		},
+		{
+			name:     "linear project keys",
+			linker:   pr.IssueLinker{Provider: "linear", ProjectKeys: []string{"eng", "OPS"}},
+			subject:  "ENG-7 and OPS-3, not SHA-1",
+			expected: []string{"ENG-7", "OPS-3"},
+		},
+		{
+			name:     "linear without projects",
+			linker:   pr.IssueLinker{Provider: "linear"},
+			subject:  "ENG-7 Add foo",
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			commits := []models.Commit{{Subject: test.subject}}
+			a.Equal(test.expected, test.linker.RelatedIssueKeys(test.branchIssue, commits))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package pr
+
+import (
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+)
+
+// TypeLabels returns the labels of a branch type (e.g. "feat"), from the configured type labels (see
+// config.PullRequestConfig.TypeLabels), or the type itself if it has none. Types are matched case-insensitively.
+func TypeLabels(typeLabels map[string][]string, branchType string) []string {
+	branchType = strings.ToLower(branchType)
+	if labels, ok := typeLabels[branchType]; ok {
+		return labels
+	}
+
+	return []string{branchType}
+}
+
+// LabelCreateArgs returns the gh arguments creating a label. Labels with a definition are created with --force,
+// so existing labels are updated to match it.
+func LabelCreateArgs(label string, definitions map[string]config.LabelConfig) []string {
+	args := []string{"label", "create", label}
+	if definition, ok := definitions[label]; ok {
+		args = append(args, "--force")
+		if definition.Color != "" {
+			args = append(args, "--color", strings.TrimPrefix(definition.Color, "#"))
+		}
+		if definition.Description != "" {
+			args = append(args, "--description", definition.Description)
+		}
+	}
+
+	return args
+}
+
+// FilterExistingLabels returns the labels that exist in the repository, matching their names case-insensitively.
+func FilterExistingLabels(labels []string, existing []string) []string {
+	existing = lo.Map(existing, func(l string, _ int) string { return strings.ToLower(strings.TrimSpace(l)) })
+
+	return lo.Filter(labels, func(label string, _ int) bool {
+		if lo.Contains(existing, strings.ToLower(label)) {
+			return true
+		}
+		log.Warnf("Label '%s' doesn't exist in the repository, skipping it", label)
+
+		return false
+	})
+}
@@ -0,0 +1,88 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/pr"
+)
+
+func Test_TypeLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		typeLabels map[string][]string
+		branchType string
+		expected   []string
+	}{
+		{name: "default mapping", branchType: "fix", expected: []string{"bug"}},
+		{name: "default mapping upper case type", branchType: "FEAT", expected: []string{"enhancement"}},
+		{name: "unmapped type", branchType: "Chore", expected: []string{"chore"}},
+		{
+			name:       "configured mapping",
+			typeLabels: map[string][]string{"fix": {"bug", "needs-qa"}},
+			branchType: "fix",
+			expected:   []string{"bug", "needs-qa"},
+		},
+		{
+			name:       "configured mapping with upper case key",
+			typeLabels: map[string][]string{"Hotfix": {"urgent"}},
+			branchType: "hotfix",
+			expected:   []string{"urgent"},
+		},
+		{
+			name:       "configured mapping without default types",
+			typeLabels: map[string][]string{"hotfix": {"urgent"}},
+			branchType: "fix",
+			expected:   []string{"fix"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prCfg := config.PullRequestConfig{TypeLabels: test.typeLabels}
+			prCfg.SetDefaults()
+
+			assert.Equal(t, test.expected, pr.TypeLabels(prCfg.TypeLabels, test.branchType))
+		})
+	}
+}
+
+func Test_LabelCreateArgs(t *testing.T) {
+	definitions := map[string]config.LabelConfig{
+		"bug":         {Color: "#d73a4a", Description: "Something isn't working"},
+		"enhancement": {Color: "a2eeef"},
+		"chore":       {},
+	}
+
+	tests := []struct {
+		label    string
+		expected []string
+	}{
+		{
+			label: "bug",
+			expected: []string{
+				"label", "create", "bug", "--force", "--color", "d73a4a", "--description", "Something isn't working",
+			},
+		},
+		{label: "enhancement", expected: []string{"label", "create", "enhancement", "--force", "--color", "a2eeef"}},
+		{label: "chore", expected: []string{"label", "create", "chore", "--force"}},
+		{label: "docs", expected: []string{"label", "create", "docs"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.label, func(t *testing.T) {
+			assert.Equal(t, test.expected, pr.LabelCreateArgs(test.label, definitions))
+		})
+	}
+}
+
+func Test_FilterExistingLabels(t *testing.T) {
+	a := assert.New(t)
+
+	existing := []string{"bug", "Enhancement", "documentation", ""}
+
+	a.Equal([]string{"Bug", "enhancement"}, pr.FilterExistingLabels([]string{"Bug", "enhancement", "chore"}, existing))
+	a.Equal([]string{}, pr.FilterExistingLabels([]string{"chore"}, existing))
+}
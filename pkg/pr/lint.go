@@ -0,0 +1,127 @@
+package pr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+)
+
+// anyTextPattern matches the output of template actions that can't be derived from a variable pattern.
+const anyTextPattern = ".+"
+
+// TitlePattern derives a regular expression matching the titles rendered by a PR title template.
+// Text is matched as is, variables (e.g. {{.Type}}) are matched by their branch variable pattern, {{if}} and {{with}}
+// blocks are optional, and anything else (e.g. variables transformed by a function) matches any text.
+func TitlePattern(titleTemplate string, variablePatterns map[string]string, funcMaps template.FuncMap) (string, error) {
+	t, err := template.New("pr-title-tpl").Funcs(funcMaps).Parse(titleTemplate)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to parse pr title template")
+	}
+
+	return "^" + nodePattern(t.Tree.Root, "", variablePatterns) + "$", nil
+}
+
+// nodePattern returns the pattern of a template node, where dot is the name of the variable {{.}} refers to.
+func nodePattern(node parse.Node, dot string, variablePatterns map[string]string) string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return ""
+		}
+		sb := strings.Builder{}
+		for _, child := range n.Nodes {
+			sb.WriteString(nodePattern(child, dot, variablePatterns))
+		}
+
+		return sb.String()
+	case *parse.TextNode:
+		return regexp.QuoteMeta(string(n.Text))
+	case *parse.ActionNode:
+		if name, ok := pipeVariable(n.Pipe, dot); ok {
+			if pattern, ok := variablePatterns[name]; ok {
+				return fmt.Sprintf("(?:%s)", pattern)
+			}
+		}
+
+		return anyTextPattern
+	case *parse.IfNode:
+		return branchPattern(n.List, n.ElseList, dot, variablePatterns)
+	case *parse.WithNode:
+		withDot, _ := pipeVariable(n.Pipe, dot)
+
+		return branchPattern(n.List, n.ElseList, withDot, variablePatterns)
+	default:
+		return ".*"
+	}
+}
+
+// branchPattern returns the pattern of a conditional block, which is optional if it has no else branch.
+func branchPattern(
+	list *parse.ListNode,
+	elseList *parse.ListNode,
+	dot string,
+	variablePatterns map[string]string,
+) string {
+	if elseList == nil {
+		return fmt.Sprintf("(?:%s)?", nodePattern(list, dot, variablePatterns))
+	}
+
+	return fmt.Sprintf("(?:%s|%s)",
+		nodePattern(list, dot, variablePatterns), nodePattern(elseList, dot, variablePatterns),
+	)
+}
+
+// pipeVariable returns the variable name of a pipeline that only evaluates a variable, e.g. {{.Issue}}.
+func pipeVariable(pipe *parse.PipeNode, dot string) (string, bool) {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return "", false
+	}
+
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		if len(arg.Ident) == 1 {
+			return arg.Ident[0], true
+		}
+	case *parse.DotNode:
+		return dot, dot != ""
+	}
+
+	return "", false
+}
+
+// LintTitle checks that a PR title matches a title pattern.
+func LintTitle(title string, pattern string) error {
+	titleRegexp, err := regexp.Compile(pattern)
+	if err != nil {
+		return errors.Wrap(err, "Failed to compile title pattern")
+	}
+
+	if !titleRegexp.MatchString(title) {
+		return errors.Errorf("Title '%s' doesn't match the pattern '%s'", title, pattern)
+	}
+
+	return nil
+}
+
+// UncheckedRequiredItems returns the required checklist items (marked with <!-- required -->) of a PR body
+// that aren't checked.
+func UncheckedRequiredItems(body string) []string {
+	items := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		matches := mdCheckboxMatcher.FindStringSubmatch(line)
+		if len(matches) == 0 || matches[1] == "x" {
+			continue
+		}
+
+		q := mdCheckboxMatcher.ReplaceAllString(line, "")
+		if requiredMarkerMatcher.MatchString(q) {
+			items = append(items, strings.TrimSpace(requiredMarkerMatcher.ReplaceAllString(q, "")))
+		}
+	}
+
+	return items
+}
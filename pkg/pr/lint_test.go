@@ -0,0 +1,76 @@
+package pr_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+func Test_TitlePattern(t *testing.T) {
+	variablePatterns := map[string]string{"Type": "fix|feat", "Issue": "[0-9]+"}
+	tests := []struct {
+		template string
+		expected string
+		valid    []string
+		invalid  []string
+	}{
+		{
+			template: config.DefaultTitle,
+			expected: `^(?:fix|feat)(?:\((?:[0-9]+)\))?: .+$`,
+			valid:    []string{"fix(123): Fix thing", "feat: Add foo"},
+			invalid:  []string{"chore: Update deps", "fix(ENG-1): Fix thing", "fix: ", "Fix thing"},
+		},
+		{
+			template: "[{{.Issue}}] {{if .Type}}{{upper .Type}}{{else}}Other{{end}} - {{.Description}}",
+			expected: `^\[(?:[0-9]+)\] (?:.+|Other) - .+$`,
+			valid:    []string{"[1] FIX - Fix thing", "[1] Other - Fix thing"},
+			invalid:  []string{"[a] FIX - Fix thing", "[1] FIX: Fix thing"},
+		},
+		{
+			template: "{{range .Issues}}{{.}} {{end}}{{.Type}}",
+			expected: `^.*(?:fix|feat)$`,
+			valid:    []string{"123 456 fix"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			a := assert.New(t)
+
+			funcMaps, err := utils.GenerateTemplateFunctions([]string{"-"})
+			a.NoError(err)
+
+			pattern, err := pr.TitlePattern(test.template, variablePatterns, funcMaps)
+			a.NoError(err)
+			a.Equal(test.expected, pattern)
+
+			titleRegexp := regexp.MustCompile(pattern)
+			for _, title := range test.valid {
+				a.True(titleRegexp.MatchString(title), title)
+				a.NoError(pr.LintTitle(title, pattern))
+			}
+			for _, title := range test.invalid {
+				a.False(titleRegexp.MatchString(title), title)
+				a.Error(pr.LintTitle(title, pattern))
+			}
+		})
+	}
+}
+
+func Test_UncheckedRequiredItems(t *testing.T) {
+	a := assert.New(t)
+
+	body := "## PR Checklist\r\n\r\n" +
+		"- [x] Tests are included <!-- required -->\r\n" +
+		"- [ ] Documentation is changed or added\r\n" +
+		"- [ ] Migration is backward compatible <!-- required -->\r\n" +
+		"* [ ] Reviewed by security <!--required-->\r\n"
+
+	a.Equal([]string{"Migration is backward compatible", "Reviewed by security"}, pr.UncheckedRequiredItems(body))
+	a.Empty(pr.UncheckedRequiredItems("- [x] Tests are included <!-- required -->"))
+}
@@ -0,0 +1,75 @@
+package pr
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/commit"
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+// TemplateMergeCommit renders the merge commit title and body of a pull request.
+// fields are the branch and pull request fields (e.g. {{.Title}}, {{.Number}}), commits are ordered from newest
+// to oldest, like git log, and coAuthors are added to the co-authors found in the commit trailers.
+func TemplateMergeCommit(
+	mergeCfg config.MergeConfig,
+	prCfg config.PullRequestConfig,
+	tokenSeparators []string,
+	issueLinker IssueLinker,
+	fields map[string]any,
+	commits []models.Commit,
+	coAuthors []string,
+) (string, string, error) {
+	funcMaps, err := utils.GenerateTemplateFunctions(tokenSeparators)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to generate template functions")
+	}
+	funcMaps = lo.Assign(funcMaps, issueLinker.TemplateFunctions())
+
+	filteredCommits, err := filterCommits(prCfg.IgnoreCommitsPatterns, commits)
+	if err != nil {
+		return "", "", err
+	}
+	filteredCommits = lo.Reverse(filteredCommits)
+
+	branchIssue, _ := fields["Issue"].(string)
+	data := lo.Assign(fields, map[string]any{
+		"Commits":       filteredCommits,
+		"Issues":        issueLinker.IssueKeys(branchIssue, commits),
+		"RelatedIssues": issueLinker.RelatedIssueKeys(branchIssue, commits),
+		"CoAuthors":     lo.UniqBy(append(coAuthors, commit.CoAuthors(filteredCommits)...), strings.ToLower),
+	})
+	data["CommitGroups"], data["BreakingChanges"] = commit.GroupCommits(filteredCommits)
+
+	title, err := executeTemplate("merge-title-tpl", mergeCfg.Title, funcMaps, data)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to template merge commit title")
+	}
+
+	body, err := executeTemplate("merge-body-tpl", mergeCfg.Body, funcMaps, data)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to template merge commit body")
+	}
+
+	return strings.TrimSpace(title), strings.TrimSpace(body), nil
+}
+
+func executeTemplate(name string, text string, funcMaps template.FuncMap, data any) (string, error) {
+	tpl, err := template.New(name).Funcs(funcMaps).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var res bytes.Buffer
+	if err := tpl.Option("missingkey=zero").Execute(&res, data); err != nil {
+		return "", err
+	}
+
+	return res.String(), nil
+}
@@ -0,0 +1,37 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+)
+
+func Test_TemplateMergeCommit(t *testing.T) {
+	a := assert.New(t)
+
+	mergeCfg := config.MergeConfig{}
+	mergeCfg.SetDefaults()
+	prCfg := config.PullRequestConfig{}
+	prCfg.SetDefaults()
+
+	title, body, err := pr.TemplateMergeCommit(
+		mergeCfg, prCfg, []string{"-"}, pr.IssueLinker{Provider: "github"},
+		map[string]any{"Type": "feat", "Issue": "12", "Description": "add-foo", "Title": "feat(12): add foo", "Number": 34},
+		[]models.Commit{
+			{Subject: "wip"},
+			{Subject: "Add bar for #14", Trailers: []models.Trailer{{Key: "Co-authored-by", Value: "Jane <jane@example.com>"}}},
+			{Subject: "Add foo", Trailers: []models.Trailer{{Key: "Refs", Value: "#13"}}},
+		},
+		[]string{"Bob <bob@example.com>"},
+	)
+
+	a.NoError(err)
+	a.Equal("feat(12): add foo (#34)", title)
+	a.Equal("* Add foo\n* Add bar for #14\n\n"+
+		"Closes #12\nRelated: #13, #14\n\n"+
+		"Co-authored-by: Bob <bob@example.com>\nCo-authored-by: Jane <jane@example.com>", body)
+}
@@ -0,0 +1,32 @@
+package pr
+
+import (
+	"github.com/caarlos0/log"
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+// ApplyPathRules adds the labels, reviewers, assignees and projects of the path rules that match
+// any of the changed files to the PR.
+func ApplyPathRules(pr *models.PullRequest, rules []config.PathRuleConfig, changedFiles []string) {
+	for _, rule := range rules {
+		matched := lo.ContainsBy(changedFiles, func(file string) bool {
+			return lo.ContainsBy(rule.Paths, func(pattern string) bool {
+				return utils.MatchGlob(pattern, file)
+			})
+		})
+		if !matched {
+			continue
+		}
+
+		log.Debugf("Path rule %v matched the changed files", rule.Paths)
+
+		pr.Labels = lo.Uniq(append(pr.Labels, rule.Labels...))
+		pr.Reviewers = lo.Uniq(append(pr.Reviewers, rule.Reviewers...))
+		pr.Assignees = lo.Uniq(append(pr.Assignees, rule.Assignees...))
+		pr.Projects = lo.Uniq(append(pr.Projects, rule.Projects...))
+	}
+}
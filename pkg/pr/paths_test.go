@@ -0,0 +1,27 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+)
+
+func Test_ApplyPathRules(t *testing.T) {
+	rules := []config.PathRuleConfig{
+		{Paths: []string{"infra/**"}, Labels: []string{"infra"}, Reviewers: []string{"org/platform"}},
+		{Paths: []string{"docs/**", "**/*.md"}, Labels: []string{"documentation"}},
+		{Paths: []string{"web/**"}, Labels: []string{"frontend"}, Assignees: []string{"@me"}},
+	}
+
+	newPR := &models.PullRequest{Labels: []string{"documentation"}}
+	pr.ApplyPathRules(newPR, rules, []string{"infra/main.tf", "README.md"})
+
+	assert.Equal(t, []string{"documentation", "infra"}, newPR.Labels)
+	assert.Equal(t, []string{"org/platform"}, newPR.Reviewers)
+	assert.Empty(t, newPR.Assignees)
+	assert.Empty(t, newPR.Projects)
+}
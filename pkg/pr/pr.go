@@ -11,34 +11,42 @@ import (
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 
+	"github.com/ilaif/gh-prx/pkg/commit"
 	"github.com/ilaif/gh-prx/pkg/config"
 	"github.com/ilaif/gh-prx/pkg/models"
 	"github.com/ilaif/gh-prx/pkg/utils"
 )
 
 var (
-	TypeToLabel = map[string]string{
-		"fix":     "bug",
-		"feat":    "enhancement",
-		"feature": "enhancement",
-		"docs":    "documentation",
-		"task":    "chore",
-	}
-
 	mdCheckboxMatcher          = regexp.MustCompile(`^\s*[\-\*]\s*\[(x|\s)\]`)
 	commitMsgSeparatorMatcher  = regexp.MustCompile(`[\*\-]`)
 	mapHasNoEntryForKeyMatcher = regexp.MustCompile(`map has no entry for key "(.*)"`)
+	requiredMarkerMatcher      = regexp.MustCompile(`(?i)\s*<!--\s*required\s*-->`)
+
+	ErrRequiredChecklistItem = errors.New("Required checklist item is not answered yes")
 )
 
 type AISummarizer func() (string, error)
 
+// ChecklistAnswer is a proposed answer to a PR checklist item.
+type ChecklistAnswer struct {
+	Answer string // One of "yes", "no" or "skip"
+	Reason string
+	Final  bool // Whether to use the answer without prompting the user
+}
+
+// ChecklistAnswerer proposes answers to PR checklist items, keyed by the checklist item question.
+type ChecklistAnswerer func(questions []string) (map[string]ChecklistAnswer, error)
+
 func TemplatePR(
 	b models.Branch,
 	prCfg config.PullRequestConfig,
 	confirm bool,
 	tokenSeparators []string,
-	commits []string,
+	issueLinker IssueLinker,
+	commits []models.Commit,
 	aiSummarizer func() (string, error),
+	checklistAnswerer ChecklistAnswerer,
 ) (*models.PullRequest, error) {
 	log.Debug("Templating PR")
 
@@ -46,6 +54,19 @@ func TemplatePR(
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to generate template functions")
 	}
+	funcMaps = lo.Assign(funcMaps, issueLinker.TemplateFunctions())
+
+	// The fields are filled below, without changing the caller's branch
+	b.Fields = lo.Assign(b.Fields)
+
+	branchIssue, _ := b.Fields["Issue"].(string)
+	if branchIssue == "" {
+		keys := issueLinker.TrailerIssueKeys(commits, append(ClosingIssueTrailers, RelatedIssueTrailers...))
+		if len(keys) > 0 {
+			log.Debug("No issue in branch name, using the issue referenced by the commit trailers: " + keys[0])
+			b.Fields["Issue"] = keys[0]
+		}
+	}
 
 	pr := &models.PullRequest{}
 
@@ -55,6 +76,7 @@ func TemplatePR(
 		return nil, errors.Wrap(err, "Failed to parse pr title template")
 	}
 
+	missingKeys := []string{}
 	for {
 		res = bytes.Buffer{}
 		err := titleTpl.Option("missingkey=error").Execute(&res, b.Fields)
@@ -67,6 +89,14 @@ func TemplatePR(
 			return nil, errors.Wrap(err, "Failed to template pr title")
 		}
 
+		if !utils.CanPrompt() {
+			// Collect all of the missing keys to report them at once
+			missingKeys = append(missingKeys, matches[1])
+			b.Fields[matches[1]] = ""
+
+			continue
+		}
+
 		log.Warn("Missing key in branch fields, prompting user to enter it manually")
 		answer := ""
 		if err := survey.AskOne(&survey.Input{
@@ -77,20 +107,47 @@ func TemplatePR(
 
 		b.Fields[matches[1]] = answer
 	}
+	if len(missingKeys) > 0 {
+		return nil, errors.Wrapf(utils.ErrCannotPrompt,
+			"Missing values for %s in the pr title template, set them with --set Key=Value",
+			strings.Join(missingKeys, ", "),
+		)
+	}
 
 	pr.Title = res.String()
 
 	bodyData := lo.Assign(b.Fields, make(map[string]any))
 	prBody := prCfg.Body
 
+	issues := issueLinker.IssueKeys(branchIssue, commits)
+	bodyData["Issues"] = issues
+	bodyData["IssueLinks"] = lo.Map(issues, func(key string, _ int) string { return issueLinker.Link(key) })
+	relatedIssues := issueLinker.RelatedIssueKeys(branchIssue, commits)
+	bodyData["RelatedIssues"] = relatedIssues
+	bodyData["RelatedIssueLinks"] = lo.Map(relatedIssues, func(key string, _ int) string {
+		return issueLinker.Link(key)
+	})
+	bodyData["CoAuthors"] = commit.CoAuthors(lo.Reverse(append([]models.Commit{}, commits...)))
+
 	if strings.Contains(prBody, ".Commits") {
-		commits, err := processCommits(prCfg.IgnoreCommitsPatterns, commits)
+		commits, err := processCommits(prCfg.IgnoreCommitsPatterns, models.CommitSubjects(commits))
 		if err != nil {
 			return nil, err
 		}
 		bodyData["Commits"] = commits
 	}
 
+	if strings.Contains(prBody, ".CommitGroups") || strings.Contains(prBody, ".BreakingChanges") ||
+		strings.Contains(prBody, ".CommitDetails") {
+		commits, err := filterCommits(prCfg.IgnoreCommitsPatterns, commits)
+		if err != nil {
+			return nil, err
+		}
+		commits = lo.Reverse(commits)
+		bodyData["CommitDetails"] = commits
+		bodyData["CommitGroups"], bodyData["BreakingChanges"] = commit.GroupCommits(commits)
+	}
+
 	aiSummary, err := aiSummarizer()
 	if err != nil {
 		return nil, err
@@ -113,29 +170,34 @@ func TemplatePR(
 	pr.Body = res.String()
 
 	if *prCfg.AnswerChecklist {
-		body, err := answerPRChecklist(pr.Body, confirm)
+		body, err := answerPRChecklist(pr.Body, confirm, checklistAnswerer)
 		if err != nil {
 			return nil, err
 		}
 		pr.Body = body
 	}
 
-	if typeAny, ok := b.Fields["Type"]; ok {
-		if typeStr, ok := typeAny.(string); ok {
-			typeStr = strings.ToLower(typeStr)
-
-			label, ok := TypeToLabel[typeStr]
-			if !ok {
-				label = typeStr
-			}
+	pr.Body = MarkGeneratedSection(pr.Body)
 
-			pr.Labels = append(pr.Labels, label)
-		}
+	if typeStr, ok := b.Fields["Type"].(string); ok {
+		pr.Labels = append(pr.Labels, TypeLabels(prCfg.TypeLabels, typeStr)...)
 	}
 
 	return pr, nil
 }
 
+// filterCommits filters out the commits whose subject matches any of the ignore patterns.
+func filterCommits(ignoreCommitsPatterns []string, commits []models.Commit) ([]models.Commit, error) {
+	ignoreCommitsMatcher, err := regexp.Compile(strings.Join(ignoreCommitsPatterns, "|"))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to compile ignore commits matcher")
+	}
+
+	return lo.Filter(commits, func(c models.Commit, _ int) bool {
+		return !ignoreCommitsMatcher.MatchString(c.Subject)
+	}), nil
+}
+
 func processCommits(ignoreCommitsPatterns []string, commits []string) ([]string, error) {
 	ignoreCommitsMatcher, err := regexp.Compile(strings.Join(ignoreCommitsPatterns, "|"))
 	if err != nil {
@@ -154,29 +216,79 @@ func processCommits(ignoreCommitsPatterns []string, commits []string) ([]string,
 	return lo.Reverse(splitCommits), nil
 }
 
-func answerPRChecklist(body string, confirm bool) (string, error) {
+func answerPRChecklist(body string, confirm bool, checklistAnswerer ChecklistAnswerer) (string, error) {
+	bodyLines := strings.Split(body, "\n")
+
+	proposedAnswers, err := proposeChecklistAnswers(bodyLines, checklistAnswerer)
+	if err != nil {
+		return "", err
+	}
+
 	if confirm {
-		log.Info("Answering yes to all checklist items (if exists in PR description)")
+		if len(proposedAnswers) > 0 {
+			log.Info("Using proposed answers for checklist items, answering no to the others (if exists in PR description)")
+		} else {
+			log.Info("Answering no to all checklist items (if exists in PR description)")
+		}
 	}
 
-	bodyLines := strings.Split(body, "\n")
 	newBodyLines := []string{}
 	for _, line := range bodyLines {
 		addLine := true
 
 		if mdCheckboxMatcher.MatchString(line) {
 			q := mdCheckboxMatcher.ReplaceAllString(line, "")
+			proposed, hasProposed := proposedAnswers[q]
 
 			answer := "no"
-			if !confirm {
-				if err := survey.AskOne(&survey.Select{
-					Message: q,
+			if hasProposed {
+				answer = proposed.Answer
+			}
+
+			required := requiredMarkerMatcher.MatchString(q)
+			question := requiredMarkerMatcher.ReplaceAllString(q, "")
+			if required {
+				question += " (required)"
+			}
+
+			if confirm || proposed.Final {
+				reason := "not answered"
+				if hasProposed {
+					reason = proposed.Reason
+				}
+				log.Infof("%s: %s (%s)", strings.TrimSpace(question), answer, reason)
+			} else {
+				prompt := &survey.Select{
+					Message: question,
 					Options: []string{"yes", "no", "skip"},
-				}, &answer, survey.WithValidator(survey.Required)); err != nil {
+				}
+				if hasProposed {
+					prompt.Default = proposed.Answer
+					prompt.Description = func(value string, _ int) string {
+						if value == proposed.Answer {
+							return "🔮 " + proposed.Reason
+						}
+
+						return ""
+					}
+				}
+
+				if err := survey.AskOne(prompt, &answer, survey.WithValidator(survey.Required)); err != nil {
 					return "", errors.Wrap(err, "Failed to ask for action item status")
 				}
 			}
 
+			if required && answer != "yes" {
+				hint := "check it to create the pull request"
+				if confirm && !proposed.Final {
+					hint = "run without --confirm to answer it"
+				}
+
+				return "", errors.Wrapf(
+					ErrRequiredChecklistItem, "'%s' is answered %s, %s", strings.TrimSpace(question), answer, hint,
+				)
+			}
+
 			switch answer {
 			case "yes":
 				line = mdCheckboxMatcher.ReplaceAllString(line, "- [x]")
@@ -194,3 +306,25 @@ func answerPRChecklist(body string, confirm bool) (string, error) {
 
 	return strings.Join(newBodyLines, "\n"), nil
 }
+
+func proposeChecklistAnswers(
+	bodyLines []string,
+	checklistAnswerer ChecklistAnswerer,
+) (map[string]ChecklistAnswer, error) {
+	if checklistAnswerer == nil {
+		return map[string]ChecklistAnswer{}, nil
+	}
+
+	questions := []string{}
+	for _, line := range bodyLines {
+		if mdCheckboxMatcher.MatchString(line) {
+			questions = append(questions, mdCheckboxMatcher.ReplaceAllString(line, ""))
+		}
+	}
+
+	if len(questions) == 0 {
+		return map[string]ChecklistAnswer{}, nil
+	}
+
+	return checklistAnswerer(questions)
+}
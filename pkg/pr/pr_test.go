@@ -0,0 +1,143 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/models"
+	"github.com/ilaif/gh-prx/pkg/pr"
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+func Test_TemplatePR_RequiredChecklistItems(t *testing.T) {
+	prCfg := config.PullRequestConfig{
+		Body: "## PR Checklist\n\n- [ ] Tests are included\n- [ ] Migration is backward compatible <!-- required -->",
+	}
+	prCfg.SetDefaults()
+	b := models.Branch{Fields: map[string]any{"Type": "feat", "Issue": "", "Description": "add-foo"}}
+	noSummary := func() (string, error) { return "", nil }
+
+	tests := []struct {
+		name         string
+		answers      map[string]pr.ChecklistAnswer
+		expectedBody string
+		err          bool
+	}{
+		{
+			name: "fails without an answer",
+			err:  true,
+		},
+		{
+			name: "fails when a required item is answered no",
+			answers: map[string]pr.ChecklistAnswer{
+				" Migration is backward compatible <!-- required -->": {Answer: "no", Final: true},
+			},
+			err: true,
+		},
+		{
+			name: "succeeds when required items are answered yes",
+			answers: map[string]pr.ChecklistAnswer{
+				" Migration is backward compatible <!-- required -->": {Answer: "yes", Final: true},
+			},
+			expectedBody: "<!-- gh-prx:start -->\n## PR Checklist\n\n- [ ] Tests are included\n" +
+				"- [x] Migration is backward compatible <!-- required -->\n<!-- gh-prx:end -->",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			answerer := func(_ []string) (map[string]pr.ChecklistAnswer, error) { return test.answers, nil }
+			newPR, err := pr.TemplatePR(b, prCfg, true, []string{"-"}, pr.IssueLinker{}, nil, noSummary, answerer)
+			if test.err {
+				a.ErrorIs(err, pr.ErrRequiredChecklistItem)
+
+				return
+			}
+
+			a.NoError(err)
+			a.Equal(test.expectedBody, newPR.Body)
+		})
+	}
+}
+
+func Test_TemplatePR_CommitGroups(t *testing.T) {
+	a := assert.New(t)
+
+	prCfg := config.PullRequestConfig{
+		Body: "{{range .CommitGroups}}### {{.Title}}\n{{range .Commits}}" +
+			"- {{with .Scope}}**{{.}}:** {{end}}{{.Description}} ({{.ShortSHA}})\n{{end}}{{end}}" +
+			"{{range .BreakingChanges}}⚠️ {{.}}\n{{end}}",
+	}
+	answerChecklist := false
+	prCfg.AnswerChecklist = &answerChecklist
+	prCfg.SetDefaults()
+	b := models.Branch{Fields: map[string]any{"Type": "feat", "Issue": "", "Description": "add-foo"}}
+
+	// Commits are ordered from newest to oldest, like git log
+	newPR, err := pr.TemplatePR(b, prCfg, true, []string{"-"}, pr.IssueLinker{}, []models.Commit{
+		{SHA: "3333333333", Subject: "wip: debugging"},
+		{SHA: "2222222222", Subject: "feat(api)!: remove v1"},
+		{SHA: "1111111111", Subject: "fix: handle nil"},
+	}, func() (string, error) { return "", nil }, nil)
+
+	a.NoError(err)
+	a.Equal("<!-- gh-prx:start -->\n"+
+		"### Features\n- **api:** remove v1 (2222222)\n"+
+		"### Bug Fixes\n- handle nil (1111111)\n"+
+		"⚠️ remove v1\n\n"+
+		"<!-- gh-prx:end -->", newPR.Body)
+}
+
+func Test_TemplatePR_CommitTrailers(t *testing.T) {
+	a := assert.New(t)
+
+	prCfg := config.PullRequestConfig{
+		Title: "{{.Type}}{{with .Issue}}({{.}}){{end}}: {{.Description}}",
+		Body: "{{range .Issues}}{{closes .}}\n{{end}}{{range .RelatedIssueLinks}}Related: {{.}}\n{{end}}" +
+			"{{range .CoAuthors}}Co-authored-by: {{.}}\n{{end}}",
+	}
+	answerChecklist := false
+	prCfg.AnswerChecklist = &answerChecklist
+	prCfg.SetDefaults()
+	b := models.Branch{Fields: map[string]any{"Type": "feat", "Issue": "", "Description": "add-foo"}}
+
+	newPR, err := pr.TemplatePR(b, prCfg, true, []string{"-"}, pr.IssueLinker{Provider: "github"}, []models.Commit{
+		{Subject: "Add bar", Trailers: []models.Trailer{
+			{Key: "Fixes", Value: "#13"},
+			{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+		}},
+		{Subject: "Add foo", Trailers: []models.Trailer{
+			{Key: "Refs", Value: "#12"},
+			{Key: "Co-authored-by", Value: "Jane Doe <jane@example.com>"},
+		}},
+	}, func() (string, error) { return "", nil }, nil)
+
+	a.NoError(err)
+	a.Equal("feat(13): add-foo", newPR.Title)
+	a.Equal("", b.Fields["Issue"])
+	a.Equal("<!-- gh-prx:start -->\n"+
+		"Closes #13\nRelated: #12\n"+
+		"Co-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: Bob <bob@example.com>\n\n"+
+		"<!-- gh-prx:end -->", newPR.Body)
+}
+
+func Test_TemplatePR_MissingKeysWithoutPrompts(t *testing.T) {
+	a := assert.New(t)
+
+	utils.DisablePrompts()
+	t.Cleanup(utils.ResetPrompts)
+
+	prCfg := config.PullRequestConfig{Title: "[{{.Team}}] {{.Type}}({{.Issue}}): {{.Description}}"}
+	prCfg.SetDefaults()
+	b := models.Branch{Fields: map[string]any{"Type": "feat", "Description": "add-foo"}}
+
+	_, err := pr.TemplatePR(
+		b, prCfg, true, []string{"-"}, pr.IssueLinker{}, nil, func() (string, error) { return "", nil }, nil,
+	)
+	a.ErrorIs(err, utils.ErrCannotPrompt)
+	a.ErrorContains(err, "Missing values for Team, Issue in the pr title template")
+}
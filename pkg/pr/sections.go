@@ -0,0 +1,103 @@
+package pr
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// GeneratedSectionStart marks the beginning of the generated part of a PR body.
+	GeneratedSectionStart = "<!-- gh-prx:start -->"
+	// GeneratedSectionEnd marks the end of the generated part of a PR body.
+	GeneratedSectionEnd = "<!-- gh-prx:end -->"
+)
+
+var ErrNoGeneratedSection = errors.New("No generated section found")
+
+// MarkGeneratedSection wraps a generated PR body with the generated section markers.
+// Bodies that already contain the markers (e.g. placed by the PR template) are returned as is.
+func MarkGeneratedSection(body string) string {
+	if strings.Contains(body, GeneratedSectionStart) {
+		return body
+	}
+
+	return GeneratedSectionStart + "\n" + body + "\n" + GeneratedSectionEnd
+}
+
+// UnmarkGeneratedSection removes the generated section markers of a PR body, keeping its content.
+func UnmarkGeneratedSection(body string) string {
+	return strings.NewReplacer(
+		GeneratedSectionStart+"\n", "", "\n"+GeneratedSectionEnd, "", GeneratedSectionStart, "", GeneratedSectionEnd, "",
+	).Replace(body)
+}
+
+// ReplaceGeneratedSection replaces the generated section of an existing PR body with the generated section
+// of a newly generated body, preserving any text outside of the markers.
+func ReplaceGeneratedSection(existingBody string, generatedBody string) (string, error) {
+	_, generated, _, ok := splitGeneratedSection(MarkGeneratedSection(generatedBody))
+	if !ok {
+		return "", errors.Wrap(ErrNoGeneratedSection, "in the generated body")
+	}
+
+	before, _, after, ok := splitGeneratedSection(existingBody)
+	if !ok {
+		return "", errors.Wrap(ErrNoGeneratedSection, "in the existing body")
+	}
+
+	return before + GeneratedSectionStart + generated + GeneratedSectionEnd + after, nil
+}
+
+// ParseChecklistAnswers returns the answers of the ticked checklist items of an existing PR body.
+// The answers are final, so they are reused as is when re-templating the PR. Unticked items aren't answered,
+// since they may just not have been reviewed yet.
+func ParseChecklistAnswers(body string) map[string]ChecklistAnswer {
+	answers := map[string]ChecklistAnswer{}
+	for _, line := range strings.Split(body, "\n") {
+		matches := mdCheckboxMatcher.FindStringSubmatch(line)
+		if len(matches) == 0 || matches[1] != "x" {
+			continue
+		}
+
+		q := mdCheckboxMatcher.ReplaceAllString(line, "")
+		answers[q] = ChecklistAnswer{Answer: "yes", Reason: "previously answered", Final: true}
+	}
+
+	return answers
+}
+
+// ApplyChecklistAnswers ticks, unticks or removes the checklist items of a PR body according to the answers.
+// Checklist items without an answer are left as is.
+func ApplyChecklistAnswers(body string, answers map[string]ChecklistAnswer) string {
+	newBodyLines := []string{}
+	for _, line := range strings.Split(body, "\n") {
+		if mdCheckboxMatcher.MatchString(line) {
+			switch answers[mdCheckboxMatcher.ReplaceAllString(line, "")].Answer {
+			case "yes":
+				line = mdCheckboxMatcher.ReplaceAllString(line, "- [x]")
+			case "no":
+				line = mdCheckboxMatcher.ReplaceAllString(line, "- [ ]")
+			case "skip":
+				continue
+			}
+		}
+
+		newBodyLines = append(newBodyLines, line)
+	}
+
+	return strings.Join(newBodyLines, "\n")
+}
+
+func splitGeneratedSection(body string) (string, string, string, bool) {
+	before, rest, ok := strings.Cut(body, GeneratedSectionStart)
+	if !ok {
+		return "", "", "", false
+	}
+
+	generated, after, ok := strings.Cut(rest, GeneratedSectionEnd)
+	if !ok {
+		return "", "", "", false
+	}
+
+	return before, generated, after, true
+}
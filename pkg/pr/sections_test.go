@@ -0,0 +1,72 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/pr"
+)
+
+func Test_ReplaceGeneratedSection(t *testing.T) {
+	tests := []struct {
+		name          string
+		existingBody  string
+		generatedBody string
+		expected      string
+		err           bool
+	}{
+		{
+			name:          "replaces the generated section and preserves the rest",
+			existingBody:  "Hand-written intro\n<!-- gh-prx:start -->\nold\n<!-- gh-prx:end -->\nReviewer notes",
+			generatedBody: "new",
+			expected:      "Hand-written intro\n<!-- gh-prx:start -->\nnew\n<!-- gh-prx:end -->\nReviewer notes",
+		},
+		{
+			name:          "keeps markers placed by the template",
+			existingBody:  "<!-- gh-prx:start -->old<!-- gh-prx:end -->",
+			generatedBody: "Static\n<!-- gh-prx:start -->new<!-- gh-prx:end -->\nIgnored",
+			expected:      "<!-- gh-prx:start -->new<!-- gh-prx:end -->",
+		},
+		{
+			name:          "fails without a generated section",
+			existingBody:  "Hand-written description",
+			generatedBody: "new",
+			err:           true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			body, err := pr.ReplaceGeneratedSection(test.existingBody, test.generatedBody)
+			if test.err {
+				a.ErrorIs(err, pr.ErrNoGeneratedSection)
+			} else {
+				a.NoError(err)
+			}
+			a.Equal(test.expected, body)
+		})
+	}
+}
+
+func Test_UnmarkGeneratedSection(t *testing.T) {
+	body := pr.UnmarkGeneratedSection("Intro\n<!-- gh-prx:start -->\ngenerated\n<!-- gh-prx:end -->\nReviewer notes")
+
+	assert.Equal(t, "Intro\ngenerated\nReviewer notes", body)
+}
+
+func Test_ParseChecklistAnswers(t *testing.T) {
+	a := assert.New(t)
+
+	answers := pr.ParseChecklistAnswers("## PR Checklist\n\n- [x] Tests are included\n- [ ] Documentation is changed")
+
+	a.Equal(map[string]pr.ChecklistAnswer{
+		" Tests are included": {Answer: "yes", Reason: "previously answered", Final: true},
+	}, answers)
+	a.Equal(
+		"- [x] Tests are included\n- [ ] Documentation is changed",
+		pr.ApplyChecklistAnswers("- [ ] Tests are included\n- [ ] Documentation is changed", answers),
+	)
+}
@@ -0,0 +1,99 @@
+package pr
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// DefaultTemplateOption is the option to pick the single pull request template (or the default body) over the
+// templates of the templates directory.
+const DefaultTemplateOption = "Default template"
+
+// Templates are the pull request templates of a repository.
+type Templates struct {
+	// Dir is the directory of multiple templates, or an empty string if it doesn't exist.
+	Dir string
+	// Files are the template file names in Dir.
+	Files []string
+	// ByType maps a branch type to a template file in Dir.
+	ByType map[string]string
+	// SinglePath is the path of the single template, or an empty string if it doesn't exist.
+	SinglePath string
+}
+
+// TemplatePicker lets the user pick one of the template options.
+type TemplatePicker func(options []string) (string, error)
+
+// SelectTemplate returns the path of the pull request template to use, or an empty string to use the default body.
+// Templates in the templates directory are selected by the branch type, either from the ByType mapping or by file
+// name (e.g. fix.md). Otherwise, the user picks a template when several exist, or with confirm, the single template
+// is used.
+func SelectTemplate(templates Templates, branchType string, confirm bool, pick TemplatePicker) (string, error) {
+	branchType = strings.ToLower(branchType)
+
+	if file, ok := templates.ByType[branchType]; ok {
+		if templates.Dir == "" {
+			return "", errors.Errorf("Failed to find pull request templates directory for type '%s'", branchType)
+		}
+
+		return filepath.Join(templates.Dir, file), nil
+	}
+
+	if file, ok := lo.Find(templates.Files, func(f string) bool {
+		return strings.EqualFold(f, branchType+".md")
+	}); ok && branchType != "" {
+		return filepath.Join(templates.Dir, file), nil
+	}
+
+	if len(templates.Files) == 0 {
+		return templates.SinglePath, nil
+	}
+	if len(templates.Files) == 1 && templates.SinglePath == "" {
+		return filepath.Join(templates.Dir, templates.Files[0]), nil
+	}
+
+	if confirm {
+		log.Warn("Multiple pull request templates found and none matches the branch type, using the default template")
+
+		return templates.SinglePath, nil
+	}
+
+	selected, err := pick(append(append([]string{}, templates.Files...), DefaultTemplateOption))
+	if err != nil {
+		return "", err
+	}
+
+	if selected == DefaultTemplateOption {
+		return templates.SinglePath, nil
+	}
+
+	return filepath.Join(templates.Dir, selected), nil
+}
+
+// ListTemplates returns the markdown file names in a templates directory, sorted.
+func ListTemplates(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list pull request templates")
+	}
+
+	files := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".md") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
@@ -0,0 +1,142 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/pr"
+)
+
+func Test_SelectTemplate(t *testing.T) {
+	dir := filepath.Join("repo", ".github", "PULL_REQUEST_TEMPLATE")
+	single := filepath.Join("repo", ".github", "pull_request_template.md")
+
+	tests := []struct {
+		name          string
+		templates     pr.Templates
+		branchType    string
+		confirm       bool
+		picked        string
+		expected      string
+		expectedPick  bool
+		expectedError bool
+	}{
+		{
+			name:       "type mapping",
+			templates:  pr.Templates{Dir: dir, Files: []string{"a.md", "b.md"}, ByType: map[string]string{"fix": "b.md"}},
+			branchType: "Fix",
+			expected:   filepath.Join(dir, "b.md"),
+		},
+		{
+			name:          "type mapping without templates dir",
+			templates:     pr.Templates{ByType: map[string]string{"fix": "b.md"}},
+			branchType:    "fix",
+			expectedError: true,
+		},
+		{
+			name:       "type file name",
+			templates:  pr.Templates{Dir: dir, Files: []string{"Docs.md", "feat.md"}, SinglePath: single},
+			branchType: "docs",
+			expected:   filepath.Join(dir, "Docs.md"),
+		},
+		{
+			name:       "single template",
+			templates:  pr.Templates{SinglePath: single},
+			branchType: "fix",
+			expected:   single,
+		},
+		{
+			name:       "no templates",
+			branchType: "fix",
+			expected:   "",
+		},
+		{
+			name:       "one template in templates dir",
+			templates:  pr.Templates{Dir: dir, Files: []string{"a.md"}},
+			branchType: "fix",
+			expected:   filepath.Join(dir, "a.md"),
+		},
+		{
+			name:       "multiple templates with confirm",
+			templates:  pr.Templates{Dir: dir, Files: []string{"a.md", "b.md"}, SinglePath: single},
+			branchType: "fix",
+			confirm:    true,
+			expected:   single,
+		},
+		{
+			name:         "multiple templates picked",
+			templates:    pr.Templates{Dir: dir, Files: []string{"a.md", "b.md"}, SinglePath: single},
+			branchType:   "fix",
+			picked:       "b.md",
+			expected:     filepath.Join(dir, "b.md"),
+			expectedPick: true,
+		},
+		{
+			name:         "multiple templates picked default",
+			templates:    pr.Templates{Dir: dir, Files: []string{"a.md"}, SinglePath: single},
+			branchType:   "fix",
+			picked:       pr.DefaultTemplateOption,
+			expected:     single,
+			expectedPick: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			picked := false
+			pick := func(options []string) (string, error) {
+				picked = true
+				a.Equal(append(append([]string{}, test.templates.Files...), pr.DefaultTemplateOption), options)
+
+				return test.picked, nil
+			}
+
+			path, err := pr.SelectTemplate(test.templates, test.branchType, test.confirm, pick)
+			if test.expectedError {
+				a.Error(err)
+
+				return
+			}
+
+			a.NoError(err)
+			a.Equal(test.expected, path)
+			a.Equal(test.expectedPick, picked)
+		})
+	}
+}
+
+func Test_SelectTemplate_PickError(t *testing.T) {
+	templates := pr.Templates{Dir: "templates", Files: []string{"a.md", "b.md"}}
+
+	_, err := pr.SelectTemplate(templates, "fix", false, func(_ []string) (string, error) {
+		return "", errors.New("interrupted")
+	})
+	assert.Error(t, err)
+}
+
+func Test_ListTemplates(t *testing.T) {
+	a := assert.New(t)
+
+	dir := t.TempDir()
+	for _, name := range []string{"feat.md", "Bug.MD", "notes.txt"} {
+		a.NoError(os.WriteFile(filepath.Join(dir, name), []byte("## Description"), 0o600))
+	}
+	a.NoError(os.Mkdir(filepath.Join(dir, "nested.md"), 0o755))
+
+	templates, err := pr.ListTemplates(dir)
+	a.NoError(err)
+	a.Equal([]string{"Bug.MD", "feat.md"}, templates)
+
+	templates, err = pr.ListTemplates("")
+	a.NoError(err)
+	a.Empty(templates)
+
+	_, err = pr.ListTemplates(filepath.Join(dir, "missing"))
+	a.Error(err)
+}
@@ -0,0 +1,104 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/ilaif/gh-prx/pkg/changelog"
+	"github.com/ilaif/gh-prx/pkg/config"
+)
+
+// Bump is a semantic version increment.
+type Bump string
+
+const (
+	BumpMajor Bump = "major"
+	BumpMinor Bump = "minor"
+	BumpPatch Bump = "patch"
+)
+
+var (
+	Bumps = []Bump{BumpMajor, BumpMinor, BumpPatch}
+
+	versionMatcher = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)$`)
+
+	ErrInvalidVersion = errors.New("Invalid semantic version")
+)
+
+// Version is a semantic version (https://semver.org), optionally prefixed with "v" like most git tags.
+type Version struct {
+	Prefix string
+	Major  int
+	Minor  int
+	Patch  int
+}
+
+// ParseVersion parses a version like "v1.2.3" or "1.2.3". Pre-release and build metadata are not supported.
+func ParseVersion(s string) (Version, error) {
+	matches := versionMatcher.FindStringSubmatch(s)
+	if len(matches) == 0 {
+		return Version{}, errors.Wrapf(ErrInvalidVersion, "'%s'", s)
+	}
+
+	numbers := lo.Map(matches[2:], func(m string, _ int) int {
+		n, _ := strconv.Atoi(m)
+
+		return n
+	})
+
+	return Version{Prefix: matches[1], Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+}
+
+// Bump returns the next version according to the increment.
+func (v Version) Bump(bump Bump) Version {
+	switch bump {
+	case BumpMajor:
+		return Version{Prefix: v.Prefix, Major: v.Major + 1}
+	case BumpMinor:
+		return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor + 1}
+	default:
+		return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+}
+
+// EntryBump returns the increment required by a merged pull request: major for breaking changes (a "!" after the
+// type in the title, or one of the major labels), minor for the minor types (e.g. feat) and patch otherwise.
+func EntryBump(entry changelog.Entry, cfg config.ReleaseConfig) Bump {
+	switch {
+	case entry.Breaking || lo.Some(entry.Labels, cfg.MajorLabels):
+		return BumpMajor
+	case lo.Contains(cfg.MinorTypes, entry.Type):
+		return BumpMinor
+	default:
+		return BumpPatch
+	}
+}
+
+// NextBump returns the largest increment required by the merged pull requests. Pull requests with any of the
+// excluded labels are left out, like they are from the release notes.
+func NextBump(entries []changelog.Entry, cfg config.ReleaseConfig, excludeLabels []string) Bump {
+	bump := BumpPatch
+	for _, entry := range entries {
+		if lo.Some(entry.Labels, excludeLabels) {
+			continue
+		}
+
+		entryBump := EntryBump(entry, cfg)
+		if entryBump == BumpMajor {
+			return BumpMajor
+		}
+		if entryBump == BumpMinor {
+			bump = BumpMinor
+		}
+	}
+
+	return bump
+}
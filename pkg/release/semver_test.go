@@ -0,0 +1,88 @@
+package release_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/changelog"
+	"github.com/ilaif/gh-prx/pkg/config"
+	"github.com/ilaif/gh-prx/pkg/release"
+)
+
+func Test_ParseVersion(t *testing.T) {
+	a := assert.New(t)
+
+	v, err := release.ParseVersion("v1.2.3")
+	a.NoError(err)
+	a.Equal(release.Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3}, v)
+	a.Equal("v1.2.3", v.String())
+
+	v, err = release.ParseVersion("10.0.1")
+	a.NoError(err)
+	a.Equal("10.0.1", v.String())
+
+	for _, invalid := range []string{"v1.2", "1.2.3-rc.1", "latest"} {
+		_, err = release.ParseVersion(invalid)
+		a.ErrorIs(err, release.ErrInvalidVersion)
+	}
+}
+
+func Test_Version_Bump(t *testing.T) {
+	v := release.Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3}
+
+	assert.Equal(t, "v2.0.0", v.Bump(release.BumpMajor).String())
+	assert.Equal(t, "v1.3.0", v.Bump(release.BumpMinor).String())
+	assert.Equal(t, "v1.2.4", v.Bump(release.BumpPatch).String())
+}
+
+func Test_NextBump(t *testing.T) {
+	cfg := config.ReleaseConfig{}
+	cfg.SetDefaults()
+
+	tests := []struct {
+		name     string
+		entries  []changelog.Entry
+		expected release.Bump
+	}{
+		{
+			name:     "no changes",
+			expected: release.BumpPatch,
+		},
+		{
+			name:     "fixes",
+			entries:  []changelog.Entry{{Type: "fix"}, {Type: "chore"}},
+			expected: release.BumpPatch,
+		},
+		{
+			name:     "feature",
+			entries:  []changelog.Entry{{Type: "fix"}, {Type: "feat"}},
+			expected: release.BumpMinor,
+		},
+		{
+			name:     "breaking change",
+			entries:  []changelog.Entry{{Type: "feat"}, {Type: "fix", Breaking: true}},
+			expected: release.BumpMajor,
+		},
+		{
+			name:     "breaking change label",
+			entries:  []changelog.Entry{{Type: "refactor", Labels: []string{"breaking-change"}}},
+			expected: release.BumpMajor,
+		},
+		{
+			name: "excluded from the changelog",
+			entries: []changelog.Entry{
+				{Type: "fix"},
+				{Type: "chore", Breaking: true, Labels: []string{"skip-changelog"}},
+				{Type: "feat", Labels: []string{"skip-changelog"}},
+			},
+			expected: release.BumpPatch,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, release.NextBump(test.entries, cfg, config.DefaultChangelogExcludeLabels))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package stack
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+const (
+	parentConfigKeySuffix = ".gh-prx-parent"
+
+	// See https://git-scm.com/docs/git-config#_description
+	gitConfigNotFoundExitCode      = 1
+	gitConfigUnsetNotFoundExitCode = 5
+
+	// NavigationStart marks the beginning of the stack navigation table in a PR body.
+	NavigationStart = "<!-- gh-prx:stack:start -->"
+	// NavigationEnd marks the end of the stack navigation table in a PR body.
+	NavigationEnd = "<!-- gh-prx:stack:end -->"
+)
+
+// Entry is a branch of a stack, along with its pull request (if one exists).
+type Entry struct {
+	Branch   string
+	PRNumber int
+	PRState  string
+}
+
+// GetParent returns the recorded parent of a branch, or an empty string if none is recorded.
+func GetParent(branch string) (string, error) {
+	out, err := utils.Exec("git", "config", "--get", parentConfigKey(branch))
+	if err != nil {
+		if gitExitCode(err) == gitConfigNotFoundExitCode {
+			return "", nil
+		}
+
+		return "", errors.Wrapf(err, "Failed to get parent of branch '%s'", branch)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// SetParent records the parent of a branch in the git config.
+func SetParent(branch string, parent string) error {
+	if _, err := utils.Exec("git", "config", parentConfigKey(branch), parent); err != nil {
+		return errors.Wrapf(err, "Failed to set parent of branch '%s' to '%s'", branch, parent)
+	}
+
+	return nil
+}
+
+// UnsetParent removes the recorded parent of a branch, removing it from its stack.
+func UnsetParent(branch string) error {
+	if _, err := utils.Exec("git", "config", "--unset", parentConfigKey(branch)); err != nil {
+		if gitExitCode(err) == gitConfigUnsetNotFoundExitCode {
+			return nil
+		}
+
+		return errors.Wrapf(err, "Failed to unset parent of branch '%s'", branch)
+	}
+
+	return nil
+}
+
+// Parents returns the recorded parents of all branches, keyed by branch.
+func Parents() (map[string]string, error) {
+	parents := map[string]string{}
+
+	out, err := utils.Exec("git", "config", "--get-regexp", `^branch\..*\.gh-prx-parent$`)
+	if err != nil {
+		if gitExitCode(err) == gitConfigNotFoundExitCode {
+			return parents, nil
+		}
+
+		return nil, errors.Wrap(err, "Failed to list branch parents")
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		key, parent, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+
+		branch := strings.TrimSuffix(strings.TrimPrefix(key, "branch."), parentConfigKeySuffix)
+		parents[branch] = parent
+	}
+
+	return parents, nil
+}
+
+// Order returns the trunk (the branch the stack is based on) and the branches of the stack containing branch,
+// ordered from the bottom to the top of the stack. Branches with several children are traversed depth-first.
+func Order(parents map[string]string, branch string) (string, []string) {
+	bottom := branch
+	visited := map[string]bool{bottom: true}
+	for {
+		parent, ok := parents[bottom]
+		if !ok {
+			return "", []string{branch} // Not part of a stack
+		}
+
+		if _, ok := parents[parent]; !ok || visited[parent] {
+			break
+		}
+
+		visited[parent] = true
+		bottom = parent
+	}
+
+	children := map[string][]string{}
+	for b, parent := range parents {
+		children[parent] = append(children[parent], b)
+	}
+
+	branches := []string{}
+	visited = map[string]bool{}
+	var walk func(b string)
+	walk = func(b string) {
+		if visited[b] {
+			return
+		}
+		visited[b] = true
+		branches = append(branches, b)
+
+		sort.Strings(children[b])
+		for _, child := range children[b] {
+			walk(child)
+		}
+	}
+	walk(bottom)
+
+	return parents[bottom], branches
+}
+
+// RenderNavigation renders a markdown navigation table of a stack, pointing at the current branch.
+func RenderNavigation(trunk string, entries []Entry, current string) string {
+	lines := []string{
+		NavigationStart,
+		fmt.Sprintf("### 📚 Stack (based on `%s`)", trunk),
+		"",
+		"| | Pull request | Branch |",
+		"|---|---|---|",
+	}
+
+	for _, e := range entries {
+		pointer := ""
+		if e.Branch == current {
+			pointer = "👉"
+		}
+
+		pr := "_Not created yet_"
+		if e.PRNumber != 0 {
+			pr = fmt.Sprintf("#%d", e.PRNumber)
+			if e.PRState == "MERGED" {
+				pr += " (merged)"
+			}
+		}
+
+		lines = append(lines, fmt.Sprintf("| %s | %s | `%s` |", pointer, pr, e.Branch))
+	}
+
+	lines = append(lines, NavigationEnd)
+
+	return strings.Join(lines, "\n")
+}
+
+// ReplaceNavigation replaces the stack navigation table of a PR body, or appends it if the body has none.
+func ReplaceNavigation(body string, navigation string) string {
+	before, rest, ok := strings.Cut(body, NavigationStart)
+	if ok {
+		if _, after, ok := strings.Cut(rest, NavigationEnd); ok {
+			return before + navigation + after
+		}
+	}
+
+	return strings.TrimRight(body, "\n") + "\n\n" + navigation
+}
+
+func parentConfigKey(branch string) string {
+	return "branch." + branch + parentConfigKeySuffix
+}
+
+func gitExitCode(err error) int {
+	exitErr := &exec.ExitError{}
+	if !errors.As(err, &exitErr) {
+		return -1
+	}
+
+	return exitErr.ExitCode()
+}
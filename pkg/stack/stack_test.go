@@ -0,0 +1,72 @@
+package stack_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/stack"
+)
+
+func Test_Order(t *testing.T) {
+	parents := map[string]string{
+		"feat/a":   "main",
+		"feat/b":   "feat/a",
+		"feat/c":   "feat/b",
+		"feat/b2":  "feat/a",
+		"fix/solo": "develop",
+	}
+
+	tests := []struct {
+		name             string
+		branch           string
+		expectedTrunk    string
+		expectedBranches []string
+	}{
+		{
+			name:             "from the top of the stack",
+			branch:           "feat/c",
+			expectedTrunk:    "main",
+			expectedBranches: []string{"feat/a", "feat/b", "feat/c", "feat/b2"},
+		},
+		{
+			name:             "from the bottom of the stack",
+			branch:           "feat/a",
+			expectedTrunk:    "main",
+			expectedBranches: []string{"feat/a", "feat/b", "feat/c", "feat/b2"},
+		},
+		{
+			name:             "single branch stack",
+			branch:           "fix/solo",
+			expectedTrunk:    "develop",
+			expectedBranches: []string{"fix/solo"},
+		},
+		{
+			name:             "not part of a stack",
+			branch:           "main",
+			expectedTrunk:    "",
+			expectedBranches: []string{"main"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trunk, branches := stack.Order(parents, test.branch)
+			assert.Equal(t, test.expectedTrunk, trunk)
+			assert.Equal(t, test.expectedBranches, branches)
+		})
+	}
+}
+
+func Test_ReplaceNavigation(t *testing.T) {
+	nav := stack.NavigationStart + "\nnew\n" + stack.NavigationEnd
+
+	assert.Equal(t,
+		"Body\n\n"+nav,
+		stack.ReplaceNavigation("Body\n", nav),
+	)
+	assert.Equal(t,
+		"Body\n"+nav+"\nFooter",
+		stack.ReplaceNavigation("Body\n"+stack.NavigationStart+"\nold\n"+stack.NavigationEnd+"\nFooter", nav),
+	)
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var gitHubPullRefMatcher = regexp.MustCompile(`^refs/pull/(\d+)/merge$`)
+
+// IsGitHubActions returns whether running in a GitHub Actions workflow.
+func IsGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// GitHubActionsPR returns the pull request that triggered a GitHub Actions workflow: its number from GITHUB_REF
+// (refs/pull/<number>/merge), or its head branch from GITHUB_HEAD_REF. Returns an empty string if there is none,
+// e.g. outside GitHub Actions or in a push workflow.
+func GitHubActionsPR() string {
+	if !IsGitHubActions() {
+		return ""
+	}
+
+	if matches := gitHubPullRefMatcher.FindStringSubmatch(os.Getenv("GITHUB_REF")); len(matches) > 0 {
+		return matches[1]
+	}
+
+	return os.Getenv("GITHUB_HEAD_REF")
+}
+
+// GitHubActionsAnnotation formats a GitHub Actions workflow command that annotates the workflow run,
+// e.g. "::error title=Title::Message". Level is one of "error", "warning" or "notice".
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions.
+func GitHubActionsAnnotation(level string, title string, message string) string {
+	escapeData := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	escapeProperty := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+	return fmt.Sprintf("::%s title=%s::%s", level, escapeProperty.Replace(title), escapeData.Replace(message))
+}
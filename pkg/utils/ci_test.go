@@ -0,0 +1,44 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+func Test_GitHubActionsAnnotation(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("::error title=Title::Message", utils.GitHubActionsAnnotation("error", "Title", "Message"))
+	a.Equal(
+		"::warning title=Lint%3A branch%2C title::100%25 wrong%0A  ^ here",
+		utils.GitHubActionsAnnotation("warning", "Lint: branch, title", "100% wrong\n  ^ here"),
+	)
+}
+
+func Test_GitHubActionsPR(t *testing.T) {
+	tests := []struct {
+		name          string
+		githubActions string
+		ref           string
+		headRef       string
+		expected      string
+	}{
+		{name: "pull request", githubActions: "true", ref: "refs/pull/123/merge", headRef: "feat/foo", expected: "123"},
+		{name: "head branch", githubActions: "true", ref: "refs/heads/main", headRef: "feat/foo", expected: "feat/foo"},
+		{name: "push", githubActions: "true", ref: "refs/heads/main", expected: ""},
+		{name: "not github actions", ref: "refs/pull/123/merge", headRef: "feat/foo", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("GITHUB_ACTIONS", test.githubActions)
+			t.Setenv("GITHUB_REF", test.ref)
+			t.Setenv("GITHUB_HEAD_REF", test.headRef)
+
+			assert.Equal(t, test.expected, utils.GitHubActionsPR())
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MatchGlob reports whether a slash-separated path matches a glob pattern.
+// "*" matches any sequence of characters except "/", "?" matches any single character except "/",
+// and "**" matches any sequence of characters, including "/". A pattern ending with "/" matches everything under it.
+func MatchGlob(pattern string, path string) bool {
+	return globToRegexp(pattern).MatchString(path)
+}
+
+func globToRegexp(pattern string) *regexp.Regexp {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			sb.WriteString("[^/]*")
+		case pattern[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String())
+}
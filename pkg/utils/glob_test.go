@@ -0,0 +1,39 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+func Test_MatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "infra/**", path: "infra/main.tf", expected: true},
+		{pattern: "infra/**", path: "infra/modules/vpc/main.tf", expected: true},
+		{pattern: "infra/**", path: "src/infra/main.tf", expected: false},
+		{pattern: "infra/", path: "infra/modules/vpc/main.tf", expected: true},
+		{pattern: "/infra/*.tf", path: "infra/main.tf", expected: true},
+		{pattern: "infra/*.tf", path: "infra/modules/main.tf", expected: false},
+		{pattern: "**/*.go", path: "main.go", expected: true},
+		{pattern: "**/*.go", path: "pkg/cmd/create.go", expected: true},
+		{pattern: "**/*.go", path: "pkg/cmd/create.go.orig", expected: false},
+		{pattern: "docs/**/*.md", path: "docs/README.md", expected: true},
+		{pattern: "docs/**/*.md", path: "docs/guides/setup.md", expected: true},
+		{pattern: "go.???", path: "go.mod", expected: true},
+		{pattern: "go.???", path: "go.sum", expected: true},
+		{pattern: "go.???", path: "go.mod.bak", expected: false},
+		{pattern: "a+b.txt", path: "a+b.txt", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, utils.MatchGlob(test.pattern, test.path))
+		})
+	}
+}
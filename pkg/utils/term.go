@@ -4,12 +4,44 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/cli/go-gh/pkg/term"
 	"github.com/pkg/errors"
 )
 
+// ErrCannotPrompt is returned when user input is needed, but prompts are disabled (see CanPrompt).
+var ErrCannotPrompt = errors.New("Cannot prompt for input in non-interactive mode")
+
+var promptsDisabled bool
+
+// DisablePrompts disables interactive prompts, e.g. with the --no-prompt flag.
+func DisablePrompts() {
+	promptsDisabled = true
+}
+
+// ResetPrompts undoes DisablePrompts, e.g. in tests.
+func ResetPrompts() {
+	promptsDisabled = false
+}
+
+// CanPrompt returns whether the user can be prompted for input: prompts aren't disabled (with DisablePrompts,
+// or with GH_PROMPT_DISABLED like gh), not running in CI (CI=true), and the standard input and output are terminals.
+func CanPrompt() bool {
+	if promptsDisabled || os.Getenv("GH_PROMPT_DISABLED") != "" {
+		return false
+	}
+
+	if ci, _ := strconv.ParseBool(os.Getenv("CI")); ci {
+		return false
+	}
+
+	return term.IsTerminal(os.Stdin) && term.IsTerminal(os.Stdout)
+}
+
 func StartSpinner(loadingMsg string, finalMsg string) *spinner.Spinner {
 	s := spinner.New(spinner.CharSets[57], 100*time.Millisecond)
 	_ = s.Color("red")
@@ -21,35 +53,44 @@ func StartSpinner(loadingMsg string, finalMsg string) *spinner.Spinner {
 	return s
 }
 
-// EditString opens the default editor with input string.
+// Editor returns the user's preferred editor command, following git's precedence.
+// The command is run by the shell, so it may contain arguments and quoted paths.
+func Editor() string {
+	for _, env := range []string{"VISUAL", "GIT_EDITOR", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+
+	return "vi"
+}
+
+// EditString opens the user's editor (see Editor) with the input string, in a temp file named by filenamePattern
+// (see os.CreateTemp), so the editor can detect the file type.
 // Returns the edited string.
-func EditString(input string) (string, error) {
-	vi := "vi"
-	tmpFile, err := os.CreateTemp(os.TempDir(), "edit-branch-name")
+func EditString(input string, filenamePattern string) (string, error) {
+	editor := Editor()
+	tmpFile, err := os.CreateTemp(os.TempDir(), filenamePattern)
 	if err != nil {
 		return "", errors.Wrapf(err, "Failed to create temp file")
 	}
 	defer tmpFile.Close()
 	defer os.Remove(tmpFile.Name())
 
-	viPath, err := exec.LookPath(vi)
-	if err != nil {
-		return "", errors.Wrapf(err, "Failed to find '%s' to edit branch name", vi)
-	}
-
 	if _, err := tmpFile.WriteString(input); err != nil {
 		return "", errors.Wrapf(err, "Failed to write to temp file")
 	}
 
-	cmd := exec.Command(viPath, tmpFile.Name())
+	// Like git, the editor is run by the shell with the file as its last argument
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, tmpFile.Name())
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Start(); err != nil {
-		return "", errors.Wrapf(err, "Failed to start '%s'", vi)
+		return "", errors.Wrapf(err, "Failed to start '%s'", editor)
 	}
 	if err := cmd.Wait(); err != nil {
-		return "", errors.Wrapf(err, "Failed to wait for '%s'", vi)
+		return "", errors.Wrapf(err, "Failed to wait for '%s'", editor)
 	}
 
 	bytes, err := os.ReadFile(tmpFile.Name())
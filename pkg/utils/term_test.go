@@ -0,0 +1,40 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilaif/gh-prx/pkg/utils"
+)
+
+func Test_EditString(t *testing.T) {
+	tests := []struct {
+		name       string
+		scriptName string
+		editor     func(script string) string
+	}{
+		{name: "plain path", scriptName: "editor", editor: func(script string) string { return script }},
+		{
+			name:       "quoted path with arguments",
+			scriptName: "my editor",
+			editor:     func(script string) string { return `"` + script + `" --wait` },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			script := filepath.Join(t.TempDir(), test.scriptName)
+			a.NoError(os.WriteFile(script, []byte("#!/bin/sh\nfor f; do :; done\necho bar >> \"$f\"\n"), 0o755)) // nolint:gosec
+			t.Setenv("VISUAL", test.editor(script))
+
+			edited, err := utils.EditString("foo\n", "test-*.md")
+			a.NoError(err)
+			a.Equal("foo\nbar\n", edited)
+		})
+	}
+}